@@ -13,13 +13,18 @@ create table `user` (
     `username` varchar(64) collate utf8mb4_general_ci not null,
     `password` varchar(64) collate utf8mb4_general_ci not null,
     `email` varchar(64) collate utf8mb4_general_ci,
-    `gender` tinyint(4) not null default '0',
+    `email_verified` tinyint(1) not null default '0' comment '邮箱是否已验证,0表示未验证,1表示已验证',
+    `gender` tinyint(4) not null default '0' comment '性别,0表示未知,1表示男,2表示女',
+    `bio` varchar(256) collate utf8mb4_general_ci not null default '' comment '个人简介',
+    `avatar_url` varchar(256) collate utf8mb4_general_ci not null default '' comment '头像地址',
+    `role` tinyint(4) not null default '0' comment '用户角色,0表示普通用户,1表示社区版主,2表示管理员',
     `create_time` timestamp null default current_timestamp,
     `update_time` timestamp null default current_timestamp on update
                 current_timestamp,
     primary key (`id`),
     unique key `idx_username` (`username`) using btree,
-    unique key `idx_user_id` (`user_id`) using btree
+    unique key `idx_user_id` (`user_id`) using btree,
+    unique key `idx_email` (`email`) using btree
 ) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci;
 
 
@@ -28,6 +33,8 @@ create table community (
     `community_id` int(10) unsigned not null,
     `community_name` varchar(128) collate utf8mb4_general_ci not null,
     `introduction` varchar(256) collate utf8mb4_general_ci not null,
+    `status` tinyint(4) not null default '1' comment '社区状态,1表示正常,0表示归档',
+    `member_num` bigint(20) not null default '0' comment '社区成员数量',
     `create_time` timestamp not null default current_timestamp,
     `update_time` timestamp not null default current_timestamp on update current_timestamp,
     primary key (`id`),
@@ -36,6 +43,62 @@ create table community (
 ) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci;
 
 
+create table `community_moderator` (
+    `id` bigint(20) not null auto_increment,
+    `community_id` bigint(20) not null comment '社区ID',
+    `user_id` bigint(20) not null comment '版主的用户ID',
+    `create_time` timestamp not null default current_timestamp,
+    primary key (`id`),
+    unique key `idx_community_user` (`community_id`, `user_id`),
+    key `idx_user_id` (`user_id`)
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='社区版主表';
+
+
+create table `community_member` (
+    `id` bigint(20) not null auto_increment,
+    `community_id` bigint(20) not null comment '社区ID',
+    `user_id` bigint(20) not null comment '成员的用户ID',
+    `create_time` timestamp not null default current_timestamp,
+    primary key (`id`),
+    unique key `idx_community_user` (`community_id`, `user_id`),
+    key `idx_user_id` (`user_id`)
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='社区成员表';
+
+
+create table `user_follow` (
+    `id` bigint(20) not null auto_increment,
+    `follower_id` bigint(20) not null comment '关注者的用户ID',
+    `followee_id` bigint(20) not null comment '被关注者的用户ID',
+    `create_time` timestamp not null default current_timestamp,
+    primary key (`id`),
+    unique key `idx_follower_followee` (`follower_id`, `followee_id`),
+    key `idx_followee_id` (`followee_id`)
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='用户关注表';
+
+
+create table `user_karma` (
+    `id` bigint(20) not null auto_increment,
+    `user_id` bigint(20) not null comment '作者的用户ID',
+    `community_id` bigint(20) not null comment '获得投票的帖子所属社区',
+    `karma` bigint(20) not null default '0' comment '作者在该社区的帖子获得的赞成票减反对票',
+    `update_time` timestamp not null default current_timestamp on update current_timestamp,
+    primary key (`id`),
+    unique key `idx_user_community` (`user_id`, `community_id`),
+    key `idx_community_id` (`community_id`)
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='用户karma表';
+
+
+create table `karma_vote` (
+    `id` bigint(20) not null auto_increment,
+    `post_id` bigint(20) not null comment '帖子ID',
+    `user_id` bigint(20) not null comment '投票的用户ID',
+    `vote_type` tinyint(1) not null comment '已计入作者karma的投票类型,0表示已取消投票',
+    `update_time` timestamp not null default current_timestamp on update current_timestamp,
+    primary key (`id`),
+    unique key `idx_post_user` (`post_id`, `user_id`) comment '保证每个用户对每个帖子的投票只计入一次karma'
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='已计入karma的投票表';
+
+
 drop table if exists `post`;
 create table `post`(
     `id` bigint(20) not null auto_increment,
@@ -53,6 +116,22 @@ create table `post`(
     key `idx_community_id` (`community_id`)
 ) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci;
 
+create table `comment` (
+    `id` bigint(20) not null auto_increment,
+    `comment_id` bigint(20) not null comment '评论id',
+    `post_id` bigint(20) not null comment '所属帖子id',
+    `author_id` bigint(20) not null comment '作者的用户id',
+    `parent_id` bigint(20) not null default '0' comment '回复的评论id,0表示直接回复帖子',
+    `content` varchar(2048) collate utf8mb4_general_ci not null comment '内容',
+    `status` tinyint(4) not null default '1' comment '评论状态',
+    `create_time` timestamp null default current_timestamp comment '创建时间',
+    `update_time` timestamp null default current_timestamp on update current_timestamp comment '更新时间',
+    primary key (`id`),
+    unique key `idx_comment_id` (`comment_id`),
+    key `idx_post_parent` (`post_id`, `parent_id`, `comment_id`) comment '加速按帖子和父评论分页查询',
+    key `idx_parent_id` (`parent_id`)
+) engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='帖子评论表';
+
 create table `vote_post` (
     `id` bigint(20) not null auto_increment comment '主键ID',
     `post_id` bigint(20) not null comment '帖子ID',
@@ -65,3 +144,17 @@ create table `vote_post` (
     key `idx_user_id` (`user_id`) comment '加速按用户查询投票记录'
 )engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='帖子投票表';
 
+create table `post_vote_archive` (
+    `id` bigint(20) not null auto_increment comment '主键ID',
+    `post_id` bigint(20) not null comment '帖子ID',
+    `score` double not null default '0' comment '投票关闭时的帖子分数',
+    `up_num` bigint(20) not null default '0' comment '赞成票数量',
+    `down_num` bigint(20) not null default '0' comment '反对票数量',
+    `archive_time` timestamp not null default current_timestamp comment '归档时间',
+    primary key (`id`),
+    unique key `idx_post_id` (`post_id`)
+)engine=InnoDB default charset=utf8mb4 collate=utf8mb4_general_ci comment='帖子投票归档表';
+
+
+-- 注册第一个用户后手动将其设置为管理员,之后可通过管理员接口设置其他用户角色
+-- update `user` set `role` = 2 where `username` = '<username>';
@@ -16,6 +16,18 @@ const (
 	CodeVoteRepeated
 	CodeInvalidPageToken
 	CodeServerBusy
+	CodeNoPermission
+	CodeCommunityNameExists
+	CodeCommunityArchived
+	CodeUserNotExists
+	CodeCommentNotExists
+	CodeVoteTimeExpired
+	CodeSessionNotExists
+	CodeWrongPassword
+	CodeInvalidResetToken
+	CodeEmailExist
+	CodeEmailNotVerified
+	CodeInvalidVerifyToken
 )
 
 var codeMsgMap = map[ResCode]string{
@@ -32,6 +44,18 @@ var codeMsgMap = map[ResCode]string{
 	CodeVoteRepeated:            "重复投票",
 	CodeInvalidPageToken:        "invalid page token",
 	CodeServerBusy:              "服务繁忙",
+	CodeNoPermission:            "没有权限",
+	CodeCommunityNameExists:     "社区名称已存在",
+	CodeCommunityArchived:       "社区已归档",
+	CodeUserNotExists:           "用户不存在",
+	CodeCommentNotExists:        "评论不存在",
+	CodeVoteTimeExpired:         "投票时间已过",
+	CodeSessionNotExists:        "登录会话不存在",
+	CodeWrongPassword:           "原密码错误",
+	CodeInvalidResetToken:       "重置密码token无效或已过期",
+	CodeEmailExist:              "邮箱已被使用",
+	CodeEmailNotVerified:        "邮箱未验证",
+	CodeInvalidVerifyToken:      "邮箱验证token无效或已过期",
 }
 
 func (c ResCode) Msg() string {
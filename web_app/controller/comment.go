@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// CreateCommentHandler 发表评论功能
+// @Summary 发表评论
+// @Description 在帖子下发表评论,或回复帖子下的其他评论
+// @Tags 评论相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "帖子ID"
+// @Param comment body models.ParamComment true "评论参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功发表评论"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 404 {object} _Response "帖子或评论不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/post/{id}/comments [post]
+func CreateCommentHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	authorID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	idStr := c.Param("id")
+	postID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamComment)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("create comment with invalid params",
+			zap.Int64("authorID", authorID),
+			zap.Error(err),
+		)
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.CreateComment(ctx, postID, authorID, p); err != nil {
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorCommentNotExist) {
+			ResponseError(c, CodeCommentNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetCommentListHandler 获得评论列表功能
+// @Summary 获取评论列表
+// @Description 按发表时间获取帖子下的评论,指定parent_id时获取该评论的回复
+// @Tags 评论相关接口
+// @Produce json
+// @Param id path string true "帖子ID"
+// @Param parent_id query string false "父评论ID"
+// @Param token query string false "pageToken"
+// @Success 200 {object} _ResponseComments "成功返回pageToken和评论列表"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "帖子不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/post/{id}/comments [get]
+func GetCommentListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	idStr := c.Param("id")
+	postID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamGetComments)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("Get comment list with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	data, err := logic.GetCommentList(ctx, postID, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidPageToken) {
+			ResponseError(c, CodeInvalidPageToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"strconv"
 	"web_app/logic"
 	"web_app/models"
@@ -71,6 +72,133 @@ func CreateCommunityHandler(c *gin.Context) {
 
 }
 
+// UpdateCommunityHandler 编辑社区功能
+// @Summary 编辑社区
+// @Description 管理员或社区版主修改社区的名称和简介
+// @Tags 社区相关接口
+// @Accept json
+// @Produce json
+// @Param id path string true "社区ID"
+// @Param community body models.ParamUpdateCommunity true "编辑社区参数"
+// @Success 200 {object} _Response "成功编辑社区"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 409 {object} _Response "社区名称已存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/update/community/{id} [put]
+// @Router /api/v2/mod/community/{id} [put]
+func UpdateCommunityHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamUpdateCommunity)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Update community with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.UpdateCommunity(ctx, id, p); err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorCommunityNameExist) {
+			ResponseError(c, CodeCommunityNameExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// ArchiveCommunityHandler 归档社区功能
+// @Summary 归档社区
+// @Description 归档社区,归档后社区不出现在社区列表中且不能发帖
+// @Tags 社区相关接口
+// @Produce json
+// @Param id path string true "社区ID"
+// @Success 200 {object} _Response "成功归档社区"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/archive/community/{id} [put]
+func ArchiveCommunityHandler(c *gin.Context) {
+	setCommunityArchived(c, true)
+}
+
+// UnarchiveCommunityHandler 取消归档社区功能
+// @Summary 取消归档社区
+// @Description 取消社区的归档状态
+// @Tags 社区相关接口
+// @Produce json
+// @Param id path string true "社区ID"
+// @Success 200 {object} _Response "成功取消归档社区"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/unarchive/community/{id} [put]
+func UnarchiveCommunityHandler(c *gin.Context) {
+	setCommunityArchived(c, false)
+}
+
+// setCommunityArchived 设置社区归档状态
+func setCommunityArchived(c *gin.Context, archived bool) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if err := logic.SetCommunityArchived(ctx, id, archived); err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetAdminCommunityListHandler 管理员获得社区列表功能
+// @Summary 管理员获取社区列表
+// @Description 获取包括已归档社区在内的所有社区及其帖子数量
+// @Tags 社区相关接口
+// @Produce json
+// @Success 200 {object} _ResponseAdminCommunityList "成功返回社区列表"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/list/community [get]
+func GetAdminCommunityListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 业务处理
+	data, err := logic.GetAdminCommunityList(ctx)
+	if err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
 // GetCommunityDetailHandler 获得社区详细信息功能
 // @Summary 获取社区详情
 // @Description 根据社区ID获取社区的详细信息
@@ -9,6 +9,13 @@ type _ResponseCommunityList struct {
 	Data    []*models.Community `json:"data"`    // 社区列表data
 }
 
+// _ResponseAdminCommunityList 返回管理员查看的社区列表
+type _ResponseAdminCommunityList struct {
+	Code    ResCode                     `json:"code"`    // 业务响应状态码
+	Message string                      `json:"message"` // 提示信息
+	Data    []*models.ApiCommunityAdmin `json:"data"`    // 社区列表data
+}
+
 // _ResponseCommunityDetail 返回社区信息详情
 type _ResponseCommunityDetail struct {
 	Code    ResCode                `json:"code"`    // 业务响应状态码
@@ -36,3 +43,38 @@ type _ResponsePosts struct {
 	Message string                `json:"message"` // 提示信息
 	Data    *models.PostsAndToken `json:"data"`    // 帖子列表和pageToken
 }
+
+// _ResponseComments 返回评论列表和pageToken
+type _ResponseComments struct {
+	Code    ResCode                  `json:"code"`    // 业务响应状态码
+	Message string                   `json:"message"` // 提示信息
+	Data    *models.CommentsAndToken `json:"data"`    // 评论列表和pageToken
+}
+
+// _ResponseUserProfile 返回用户主页信息
+type _ResponseUserProfile struct {
+	Code    ResCode                `json:"code"`    // 业务响应状态码
+	Message string                 `json:"message"` // 提示信息
+	Data    *models.ApiUserProfile `json:"data"`    // 用户主页信息
+}
+
+// _ResponseMyProfile 返回当前用户的个人资料
+type _ResponseMyProfile struct {
+	Code    ResCode             `json:"code"`    // 业务响应状态码
+	Message string              `json:"message"` // 提示信息
+	Data    *models.UserProfile `json:"data"`    // 个人资料
+}
+
+// _ResponseKarmaLeaderboard 返回karma排行榜
+type _ResponseKarmaLeaderboard struct {
+	Code    ResCode                `json:"code"`    // 业务响应状态码
+	Message string                 `json:"message"` // 提示信息
+	Data    []*models.ApiUserKarma `json:"data"`    // 排行榜
+}
+
+// _ResponseSessions 返回登录会话列表
+type _ResponseSessions struct {
+	Code    ResCode           `json:"code"`    // 业务响应状态码
+	Message string            `json:"message"` // 提示信息
+	Data    []*models.Session `json:"data"`    // 登录会话列表
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"errors"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// VerifyEmailHandler 验证邮箱功能
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的token激活邮箱,token只能使用一次
+// @Tags 用户相关接口
+// @Accept json
+// @Produce json
+// @Param object body models.ParamVerifyEmail true "验证邮箱参数"
+// @Success 200 {object} _Response "成功验证邮箱"
+// @Failure 400 {object} _Response "参数错误或token无效"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/verify-email [post]
+func VerifyEmailHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	p := new(models.ParamVerifyEmail)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Verify email with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.VerifyEmail(ctx, p); err != nil {
+		if errors.Is(err, logic.ErrorInvalidVerifyToken) {
+			ResponseError(c, CodeInvalidVerifyToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// SendVerifyEmailHandler 重新发送验证邮件功能
+// @Summary 重新发送验证邮件
+// @Description 向当前用户资料中的邮箱重新发送验证邮件
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功发送验证邮件"
+// @Failure 400 {object} _Response "没有设置邮箱或邮箱已验证"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/me/verify-email [post]
+func SendVerifyEmailHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理
+	if err = logic.SendVerifyEmail(ctx, userID); err != nil {
+		if errors.Is(err, logic.ErrorEmailRequired) || errors.Is(err, logic.ErrorEmailAlreadyVerified) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// FollowHandler 关注用户功能
+// @Summary 关注用户
+// @Description 关注用户,已经关注时忽略,关注后该用户发布的帖子出现在时间线中
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "被关注的用户ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功关注用户"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/user/{id}/follow [post]
+func FollowHandler(c *gin.Context) {
+	setFollow(c, true)
+}
+
+// UnfollowHandler 取消关注用户功能
+// @Summary 取消关注用户
+// @Description 取消关注用户,没有关注时忽略
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "被关注的用户ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功取消关注"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/user/{id}/follow [delete]
+func UnfollowHandler(c *gin.Context) {
+	setFollow(c, false)
+}
+
+// setFollow 关注或取消关注用户
+func setFollow(c *gin.Context, follow bool) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	idStr := c.Param("id")
+	followeeID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if follow {
+		err = logic.Follow(ctx, userID, followeeID)
+	} else {
+		err = logic.Unfollow(ctx, userID, followeeID)
+	}
+	if err != nil {
+		if errors.Is(err, logic.ErrorFollowSelf) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetTimelineHandler 获得关注的人发布的帖子列表功能
+// @Summary 获取关注时间线
+// @Description 获取当前用户关注的人发布的帖子,按发帖时间倒序
+// @Tags 帖子相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param token query string false "pageToken,使用返回的next_token或prev_token"
+// @Param page_size query int false "每页数量"
+// @Security ApiKeyAuth
+// @Success 200 {object} _ResponsePosts "成功返回pageToken和帖子列表"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/timeline [get]
+func GetTimelineHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	p := new(models.ParamGetTimeline)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("Get timeline with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	data, err := logic.GetTimeline(ctx, userID, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorInvalidPageToken) {
+			ResponseError(c, CodeInvalidPageToken)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidPageSize) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
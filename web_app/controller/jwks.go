@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"net/http"
+	"web_app/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler 公开JWT验证公钥功能
+// @Summary 获取JWT公钥集合
+// @Description 以JWKS格式返回所有非对称签名密钥的公钥,其他服务可根据token头部的kid验证Lightning的AccessToken
+// @Tags 用户相关接口
+// @Produce json
+// @Success 200 {object} jwt.JSONWebKeySet "成功返回公钥集合"
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwt.PublicKeySet())
+}
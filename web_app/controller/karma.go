@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// GetKarmaLeaderboardHandler 获得karma排行榜功能
+// @Summary 获取karma排行榜
+// @Description 获取全站或社区中帖子获得的赞成票减反对票最多的用户
+// @Tags 用户相关接口
+// @Produce json
+// @Param community_id query string false "社区ID,不填时获取全站排行榜"
+// @Param limit query int false "返回的用户数量,默认10,最多100"
+// @Success 200 {object} _ResponseKarmaLeaderboard "成功返回排行榜"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/leaderboard/users [get]
+func GetKarmaLeaderboardHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	p := new(models.ParamGetLeaderboard)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("Get leaderboard with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	data, err := logic.GetKarmaLeaderboard(ctx, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
+// RebuildKarmaHandler 重建用户karma功能
+// @Summary 重建用户karma
+// @Description 管理员从数据库投票表重新统计所有作者的karma并写入mysql和redis
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Security	ApiKeyAuth
+// @Success 200 {object} _Response "重建成功"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/rebuild/karma [put]
+func RebuildKarmaHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 业务处理
+	if err := logic.RebuildKarma(ctx); err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// JoinCommunityHandler 加入社区功能
+// @Summary 加入社区
+// @Description 当前用户加入社区,已经是成员时忽略
+// @Tags 社区相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "社区ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功加入社区"
+// @Failure 400 {object} _Response "参数错误或社区已归档"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/community/{id}/join [post]
+func JoinCommunityHandler(c *gin.Context) {
+	setCommunityMember(c, true)
+}
+
+// LeaveCommunityHandler 退出社区功能
+// @Summary 退出社区
+// @Description 当前用户退出社区,不是成员时忽略
+// @Tags 社区相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "社区ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功退出社区"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "社区不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/community/{id}/join [delete]
+func LeaveCommunityHandler(c *gin.Context) {
+	setCommunityMember(c, false)
+}
+
+// setCommunityMember 加入或退出社区
+func setCommunityMember(c *gin.Context, join bool) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if join {
+		err = logic.JoinCommunity(ctx, id, userID)
+	} else {
+		err = logic.LeaveCommunity(ctx, id, userID)
+	}
+	if err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorCommunityArchived) {
+			ResponseError(c, CodeCommunityArchived)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetJoinedCommunityListHandler 获得我加入的社区列表功能
+// @Summary 获取我加入的社区
+// @Description 获取当前用户加入的未归档社区列表
+// @Tags 社区相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _ResponseCommunityList "成功返回社区列表"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/me/communities [get]
+func GetJoinedCommunityListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理
+	data, err := logic.GetJoinedCommunityList(ctx, userID)
+	if err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
+// GetFeedHandler 获得我加入的社区的帖子列表功能
+// @Summary 获取首页信息流
+// @Description 合并当前用户加入的社区的帖子,按时间或分数排序
+// @Tags 帖子相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param order query string false "排序方式(time、score、gravity、hot或best),默认score"
+// @Param token query string false "pageToken,使用返回的next_token或prev_token"
+// @Param page_size query int false "每页数量"
+// @Security ApiKeyAuth
+// @Success 200 {object} _ResponsePosts "成功返回pageToken和帖子列表"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/feed [get]
+func GetFeedHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	p := new(models.ParamGetFeed)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("Get feed with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	data, err := logic.GetFeed(ctx, userID, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorInvalidPageToken) {
+			ResponseError(c, CodeInvalidPageToken)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidOrder) || errors.Is(err, logic.ErrorInvalidPageSize) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"errors"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// ChangePasswordHandler 修改密码功能
+// @Summary 修改密码
+// @Description 校验原密码后设置新密码,新密码需要满足密码策略,修改后其他设备上的会话失效
+// @Tags 用户相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param object body models.ParamChangePassword true "修改密码参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功修改密码"
+// @Failure 400 {object} _Response "参数错误或原密码错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/me/password [put]
+func ChangePasswordHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	sessionID, _ := GetCurrentSessionID(c)
+	p := new(models.ParamChangePassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Change password with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err = logic.ChangePassword(ctx, userID, sessionID, p); err != nil {
+		if errors.Is(err, logic.ErrorInvalidPassword) {
+			ResponseError(c, CodeWrongPassword)
+			return
+		}
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// RequestPasswordResetHandler 申请重置密码功能
+// @Summary 申请重置密码
+// @Description 向用户的邮箱发送重置密码token,用户不存在或没有设置邮箱时同样返回成功
+// @Tags 用户相关接口
+// @Accept json
+// @Produce json
+// @Param object body models.ParamRequestPasswordReset true "申请重置密码参数"
+// @Success 200 {object} _Response "已受理"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/password/reset/request [post]
+func RequestPasswordResetHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	p := new(models.ParamRequestPasswordReset)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Request password reset with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.RequestPasswordReset(ctx, p); err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// ResetPasswordHandler 重置密码功能
+// @Summary 重置密码
+// @Description 使用邮件中的token设置新密码,token只能使用一次,重置后用户的所有会话失效
+// @Tags 用户相关接口
+// @Accept json
+// @Produce json
+// @Param object body models.ParamResetPassword true "重置密码参数"
+// @Success 200 {object} _Response "成功重置密码"
+// @Failure 400 {object} _Response "参数错误或token无效"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/password/reset [post]
+func ResetPasswordHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	p := new(models.ParamResetPassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Reset password with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.ResetPassword(ctx, p); err != nil {
+		if errors.Is(err, logic.ErrorInvalidResetToken) {
+			ResponseError(c, CodeInvalidResetToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
@@ -23,6 +23,8 @@ import (
 // @Success 200 {object} _Response "成功创建帖子"
 // @Failure 400 {object} _Response "参数错误"
 // @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "社区已归档或邮箱未验证"
+// @Failure 404 {object} _Response "社区不存在"
 // @Failure 500 {object} _Response "服务器繁忙"
 // @Router /api/v2/post [post]
 func CreatePostHandler(c *gin.Context) {
@@ -49,6 +51,18 @@ func CreatePostHandler(c *gin.Context) {
 	}
 	// 业务处理
 	if err := logic.CreatePost(ctx, p, authorID); err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorCommunityArchived) {
+			ResponseError(c, CodeCommunityArchived)
+			return
+		}
+		if errors.Is(err, logic.ErrorEmailNotVerified) {
+			ResponseError(c, CodeEmailNotVerified)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -91,14 +105,170 @@ func GetPostDetailHandler(c *gin.Context) {
 	ResponseSuccess(c, data)
 }
 
+// UpdatePostHandler 编辑帖子功能
+// @Summary 编辑帖子
+// @Description 作者编辑自己帖子的标题和内容
+// @Tags 帖子相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "帖子ID"
+// @Param post body models.ParamUpdatePost true "编辑帖子参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功编辑帖子"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "帖子不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/post/{id} [put]
+func UpdatePostHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	idStr := c.Param("id")
+	postID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamUpdatePost)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("update post with invalid params",
+			zap.Int64("userID", userID),
+			zap.Error(err),
+		)
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.UpdatePost(ctx, postID, userID, p); err != nil {
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorNoPermission) {
+			ResponseError(c, CodeNoPermission)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// DeletePostHandler 删除帖子功能
+// @Summary 删除帖子
+// @Description 作者删除自己的帖子(软删除)
+// @Tags 帖子相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "帖子ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功删除帖子"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "帖子不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/post/{id} [delete]
+func DeletePostHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	idStr := c.Param("id")
+	postID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if err := logic.DeletePost(ctx, postID, userID); err != nil {
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorNoPermission) {
+			ResponseError(c, CodeNoPermission)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// ModDeletePostHandler 版主删除帖子功能
+// @Summary 版主删除帖子
+// @Description 管理员或社区版主删除社区中的帖子(软删除)
+// @Tags 帖子相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "社区ID"
+// @Param post_id path string true "帖子ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功删除帖子"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "帖子不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/mod/community/{id}/post/{post_id} [delete]
+func ModDeletePostHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if err := logic.ModDeletePost(ctx, communityID, postID); err != nil {
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
 // GetPostListHandler 获得帖子列表功能
 // @Summary 获取帖子列表
-// @Description 获取按时间或分数排序的帖子列表
+// @Description 获取社区内或全站按时间或分数排序的帖子列表
 // @Tags 帖子相关接口
 // @Produce json
-// @Param order query string false "排序方式(time或score)"
-// @Param token query string false "pageToken"
-// @Param community_id query string true "社区ID"
+// @Param order query string false "排序方式(time、score、gravity、hot或best),默认score"
+// @Param token query string false "pageToken,使用返回的next_token或prev_token"
+// @Param page_size query int false "每页数量"
+// @Param community_id query string false "社区ID,不填时获取全站帖子"
+// @Param exclude_archived query bool false "获取全站帖子时是否排除已归档社区的帖子"
 // @Success 200 {object} _ResponsePosts "成功返回pageToken和帖子列表"
 // @Failure 400 {object} _Response "参数错误"
 // @Failure 500 {object} _Response "服务器繁忙"
@@ -122,7 +292,11 @@ func GetPostListHandler(c *gin.Context) {
 	data, err := logic.GetPostList(ctx, p)
 	if err != nil {
 		if errors.Is(err, logic.ErrorInvalidPageToken) {
-			ResponseError(c, CodeInvalidToken)
+			ResponseError(c, CodeInvalidPageToken)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidOrder) || errors.Is(err, logic.ErrorInvalidPageSize) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
 			return
 		}
 		ResponseError(c, CodeServerBusy)
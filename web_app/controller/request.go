@@ -6,7 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-const CtxUserIDKey = "user_id"
+const (
+	CtxUserIDKey       = "user_id"
+	CtxUserRoleKey     = "user_role"
+	CtxCommunityIDsKey = "community_ids"
+	CtxSessionIDKey    = "session_id"
+)
 
 var ErrorNeedLogin = errors.New("未登录")
 
@@ -24,3 +29,43 @@ func GetCurrentUserID(c *gin.Context) (userID int64, err error) {
 	}
 	return
 }
+
+// GetCurrentUserRole 获得当前用户角色
+func GetCurrentUserRole(c *gin.Context) (role int8, err error) {
+	v, exists := c.Get(CtxUserRoleKey)
+	if !exists {
+		err = ErrorNeedLogin
+		return
+	}
+	role, ok := v.(int8)
+	if !ok {
+		err = ErrorNeedLogin
+		return
+	}
+	return
+}
+
+// GetCurrentSessionID 获得当前请求使用的登录会话ID
+func GetCurrentSessionID(c *gin.Context) (sessionID string, err error) {
+	v, exists := c.Get(CtxSessionIDKey)
+	if !exists {
+		err = ErrorNeedLogin
+		return
+	}
+	sessionID, ok := v.(string)
+	if !ok || sessionID == "" {
+		err = ErrorNeedLogin
+		return
+	}
+	return
+}
+
+// GetCurrentUserCommunityIDs 获得当前用户担任版主的社区ID
+func GetCurrentUserCommunityIDs(c *gin.Context) (communityIDs []int64) {
+	v, exists := c.Get(CtxCommunityIDsKey)
+	if !exists {
+		return nil
+	}
+	communityIDs, _ = v.([]int64)
+	return communityIDs
+}
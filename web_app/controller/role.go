@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"web_app/logic"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// SetUserRoleHandler 设置用户角色功能
+// @Summary 设置用户角色
+// @Description 管理员设置用户为管理员或普通用户,角色变更在用户刷新token后生效
+// @Tags 权限相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "用户ID"
+// @Param role body models.ParamSetUserRole true "角色参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "设置成功"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/set/user/role/{id} [put]
+func SetUserRoleHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamSetUserRole)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Set user role with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.SetUserRole(ctx, userID, p.Role); err != nil {
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// AddModeratorHandler 指派社区版主功能
+// @Summary 指派社区版主
+// @Description 管理员指派用户为社区版主
+// @Tags 权限相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "社区ID"
+// @Param moderator body models.ParamModerator true "版主参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "指派成功"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "社区或用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/add/community/moderator/{id} [post]
+func AddModeratorHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamModerator)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Add moderator with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.AddModerator(ctx, communityID, p.UserID); err != nil {
+		if errors.Is(err, logic.ErrorCommunityNotExist) {
+			ResponseError(c, CodeCommunityNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// RemoveModeratorHandler 取消社区版主功能
+// @Summary 取消社区版主
+// @Description 管理员取消用户的社区版主身份
+// @Tags 权限相关接口
+// @Produce json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "社区ID"
+// @Param user_id path string true "用户ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "取消成功"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/delete/community/moderator/{id}/{user_id} [delete]
+func RemoveModeratorHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	if err := logic.RemoveModerator(ctx, communityID, userID); err != nil {
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
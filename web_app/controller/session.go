@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"web_app/logic"
+	"web_app/settings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshTokenHandler 刷新AccessToken功能
+// @Summary 刷新AccessToken
+// @Description 使用Cookie中的RefreshToken换取新的AccessToken,同时轮换RefreshToken Cookie
+// @Tags 用户相关接口
+// @Produce json
+// @Success 200 {object} _Response "成功返回新的accessToken"
+// @Failure 401 {object} _Response "未登录或RefreshToken无效"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/token/refresh [post]
+func RefreshTokenHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 从Cookie获取refreshToken
+	refreshToken, err := c.Cookie(logic.RefreshCookieName)
+	if err != nil || refreshToken == "" {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理,验证refreshToken并生成新的accessToken和refreshToken
+	accessToken, newRefreshToken, _, err := logic.ValidateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, logic.ErrorRefreshTokenNotExist) ||
+			errors.Is(err, logic.ErrorInvalidRefeshToken) ||
+			errors.Is(err, logic.ErrorRefreshTokenReused) {
+			// 会话已失效,删除客户端的refreshToken
+			ClearRefreshCookie(c)
+			ResponseError(c, CodeInvalidToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应,下发轮换后的refreshToken
+	SetRefreshCookie(c, newRefreshToken, int(settings.Conf.RefreshTokenDuration.Seconds()))
+	ResponseSuccess(c, accessToken)
+}
+
+// LogoutHandler 退出登录功能
+// @Summary 退出登录
+// @Description 撤销当前会话,当前会话的AccessToken和RefreshToken立即失效,不影响其他设备
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功退出登录"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/logout [post]
+func LogoutHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	sessionID, err := GetCurrentSessionID(c) // 获得当前会话ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理
+	if err = logic.RevokeSession(ctx, userID, sessionID); err != nil && !errors.Is(err, logic.ErrorSessionNotExist) {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 清除RefreshToken Cookie
+	ClearRefreshCookie(c)
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetSessionListHandler 获得登录会话列表功能
+// @Summary 获取登录会话列表
+// @Description 获取当前用户所有未过期的登录会话,current标记当前请求使用的会话
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _ResponseSessions "成功返回登录会话列表"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/sessions [get]
+func GetSessionListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	sessionID, _ := GetCurrentSessionID(c)
+	// 业务处理
+	data, err := logic.GetSessions(ctx, userID, sessionID)
+	if err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
+// RevokeSessionHandler 撤销登录会话功能
+// @Summary 撤销登录会话
+// @Description 撤销当前用户的一个登录会话,该会话的token立即失效
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param id path string true "会话ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功撤销会话"
+// @Failure 404 {object} _Response "登录会话不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/sessions/{id} [delete]
+func RevokeSessionHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	sessionID := c.Param("id")
+	// 业务处理
+	if err = logic.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, logic.ErrorSessionNotExist) {
+			ResponseError(c, CodeSessionNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 撤销的是当前会话时清除RefreshToken Cookie
+	if currentSessionID, _ := GetCurrentSessionID(c); currentSessionID == sessionID {
+		ClearRefreshCookie(c)
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// RevokeAllSessionsHandler 撤销所有登录会话功能
+// @Summary 退出所有设备
+// @Description 撤销当前用户的所有登录会话,包括当前会话
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功撤销所有会话"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/sessions [delete]
+func RevokeAllSessionsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理
+	if err = logic.RevokeAllSessions(ctx, userID); err != nil {
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ClearRefreshCookie(c)
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"web_app/logic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRefreshTokenHandlerInvalid 没有refreshToken时需要登录,refreshToken无效时返回CodeInvalidToken并删除Cookie
+func TestRefreshTokenHandlerInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v2/token/refresh", RefreshTokenHandler)
+
+	cases := []struct {
+		name        string
+		cookie      *http.Cookie
+		wantCode    ResCode
+		wantCleared bool
+	}{
+		{"no cookie", nil, CodeNeedLogin, false},
+		{"invalid refresh token", &http.Cookie{Name: logic.RefreshCookieName, Value: "invalid"}, CodeInvalidToken, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/token/refresh", nil)
+			if tc.cookie != nil {
+				req.AddCookie(tc.cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var resp ResponseData
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tc.wantCode {
+				t.Errorf("code = %d, want %d", resp.Code, tc.wantCode)
+			}
+			var cleared bool
+			for _, cookie := range w.Result().Cookies() {
+				if cookie.Name == logic.RefreshCookieName && cookie.MaxAge < 0 {
+					cleared = true
+				}
+			}
+			if cleared != tc.wantCleared {
+				t.Errorf("cookie cleared = %v, want %v", cleared, tc.wantCleared)
+			}
+		})
+	}
+}
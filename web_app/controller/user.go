@@ -2,6 +2,7 @@ package controller
 
 import (
 	"errors"
+	"strconv"
 	"web_app/logic"
 	"web_app/models"
 	"web_app/settings"
@@ -13,13 +14,13 @@ import (
 
 // SignUpHandler 处理注册请求的函数
 // @Summary 用户注册
-// @Description 用户注册接口
+// @Description 用户注册接口,填写邮箱时发送验证邮件,账号以邮箱未验证状态创建
 // @Tags 用户相关接口
 // @Accept json
 // @Produce json
 // @Param user body models.ParamSignUp true "注册参数"
 // @Success 200 {object} _Response "注册成功"
-// @Failure 400 {object} _Response "参数错误"
+// @Failure 400 {object} _Response "参数错误或邮箱已被使用"
 // @Failure 500 {object} _Response "服务器繁忙"
 // @Router /api/v2/signup [post]
 func SignUpHandler(c *gin.Context) {
@@ -47,6 +48,14 @@ func SignUpHandler(c *gin.Context) {
 			ResponseError(c, CodeUsernameExist)
 			return
 		}
+		if errors.Is(err, logic.ErrorEmailExist) {
+			ResponseError(c, CodeEmailExist)
+			return
+		}
+		if errors.Is(err, logic.ErrorEmailRequired) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -85,7 +94,7 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 	// 2.业务处理,在logic层校验用户名是否存在，密码是否正确
-	accessToken, refreshToken, err := logic.Login(ctx, p)
+	accessToken, refreshToken, err := logic.Login(ctx, p, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		// 用户名不存在或密码错误
 		if errors.Is(err, logic.ErrorUserNotExist) || errors.Is(err, logic.ErrorInvalidPassword) {
@@ -98,16 +107,198 @@ func LoginHandler(c *gin.Context) {
 	}
 	// 3.返回响应
 	// 设置 HttpOnly Cookie
+	SetRefreshCookie(c, refreshToken, int(settings.Conf.RefreshTokenDuration.Seconds()))
+	// 返回 accessToken
+	ResponseSuccess(c, accessToken)
+}
+
+// SetRefreshCookie 将refreshToken写入HttpOnly Cookie,maxAge小于0时删除Cookie
+func SetRefreshCookie(c *gin.Context, refreshToken string, maxAge int) {
 	secure := settings.Conf.Mode == "release" // 判断是不是发布者模式
 	c.SetCookie(
 		logic.RefreshCookieName,
 		refreshToken,
-		int(settings.Conf.RefreshTokenDuration.Seconds()),
+		maxAge,
 		logic.RefreshCookiePath,
 		logic.RefreshCookieDomain,
 		secure,
 		logic.RefreshCookieHttpOnly,
 	)
-	// 返回 accessToken
-	ResponseSuccess(c, accessToken)
+}
+
+// ClearRefreshCookie 删除客户端的refreshToken Cookie
+func ClearRefreshCookie(c *gin.Context) {
+	SetRefreshCookie(c, "", -1)
+}
+
+// GetUserProfileHandler 获得用户主页信息功能
+// @Summary 获取用户主页
+// @Description 获取用户的公开资料、帖子数量、karma、粉丝数量和关注的人数
+// @Tags 用户相关接口
+// @Produce json
+// @Param id path string true "用户ID"
+// @Success 200 {object} _ResponseUserProfile "成功返回用户主页信息"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/user/{id} [get]
+func GetUserProfileHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	idStr := c.Param("id")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	// 业务处理
+	data, err := logic.GetUserProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
+// GetMyProfileHandler 获得当前用户的个人资料功能
+// @Summary 获取我的资料
+// @Description 获取当前用户的邮箱、性别、简介和头像
+// @Tags 用户相关接口
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Security ApiKeyAuth
+// @Success 200 {object} _ResponseMyProfile "成功返回个人资料"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/me [get]
+func GetMyProfileHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	// 业务处理
+	data, err := logic.GetMyProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
+}
+
+// UpdateMyProfileHandler 编辑当前用户的个人资料功能
+// @Summary 编辑我的资料
+// @Description 修改当前用户的邮箱、性别、简介和头像,未填写的字段会被清空
+// @Tags 用户相关接口
+// @Accept json
+// @Produce json
+// @Param Authorization	header string false "Bearer 用户令牌"
+// @Param profile body models.ParamUpdateProfile true "个人资料参数"
+// @Security ApiKeyAuth
+// @Success 200 {object} _Response "成功编辑个人资料"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/me [put]
+func UpdateMyProfileHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	userID, err := GetCurrentUserID(c) // 获得当前用户ID
+	if err != nil {
+		ResponseError(c, CodeNeedLogin)
+		return
+	}
+	p := new(models.ParamUpdateProfile)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("Update profile with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	if err := logic.UpdateMyProfile(ctx, userID, p); err != nil {
+		if errors.Is(err, logic.ErrorEmailExist) {
+			ResponseError(c, CodeEmailExist)
+			return
+		}
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// GetUserPostListHandler 获得用户发布的帖子列表功能
+// @Summary 获取用户的帖子
+// @Description 获取用户发布的帖子,按发帖时间倒序
+// @Tags 用户相关接口
+// @Produce json
+// @Param id path string true "用户ID"
+// @Param token query string false "pageToken,使用返回的next_token或prev_token"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} _ResponsePosts "成功返回pageToken和帖子列表"
+// @Failure 400 {object} _Response "参数错误"
+// @Failure 404 {object} _Response "用户不存在"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /api/v2/user/{id}/posts [get]
+func GetUserPostListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 参数获取和参数检验
+	idStr := c.Param("id")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
+		ResponseError(c, CodeInvalidParam)
+		return
+	}
+	p := new(models.ParamGetUserPosts)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("Get user posts with invalid params", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParam)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParam, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	// 业务处理
+	data, err := logic.GetUserPostList(ctx, userID, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidPageToken) {
+			ResponseError(c, CodeInvalidPageToken)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidPageSize) {
+			ResponseErrorWithMsg(c, CodeInvalidParam, err.Error())
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, data)
 }
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+	"web_app/settings"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/locales/en"
@@ -31,6 +34,11 @@ func InitTrans(locale string) (err error) {
 			return name
 		})
 
+		// 注册密码策略校验
+		if err = v.RegisterValidation("password", validatePassword); err != nil {
+			return err
+		}
+
 		zhT := zh.New() // 中文翻译器
 		enT := en.New() // 英文翻译器
 
@@ -56,11 +64,87 @@ func InitTrans(locale string) (err error) {
 		default:
 			err = enTranslations.RegisterDefaultTranslations(v, trans)
 		}
+		if err != nil {
+			return err
+		}
+		// 注册密码策略的提示信息
+		err = v.RegisterTranslation("password", trans,
+			func(ut ut.Translator) error {
+				return ut.Add("password", "{0}不符合密码策略,{1}", true)
+			},
+			func(ut ut.Translator, fe validator.FieldError) string {
+				t, _ := ut.T("password", fe.Field(), passwordPolicy(settings.Conf.PasswordConfig))
+				return t
+			},
+		)
 		return
 	}
 	return
 }
 
+// validatePassword 根据配置的密码策略校验密码
+func validatePassword(fl validator.FieldLevel) bool {
+	return checkPassword(fl.Field().String(), settings.Conf.PasswordConfig)
+}
+
+// checkPassword 判断密码是否满足密码策略,最大长度按字节计算
+func checkPassword(password string, cfg *settings.PasswordConfig) bool {
+	if cfg == nil {
+		return password != ""
+	}
+	if utf8.RuneCountInString(password) < cfg.MinLength {
+		return false
+	}
+	if cfg.MaxLength > 0 && len(password) > cfg.MaxLength {
+		return false
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	return (!cfg.RequireUpper || hasUpper) &&
+		(!cfg.RequireLower || hasLower) &&
+		(!cfg.RequireDigit || hasDigit) &&
+		(!cfg.RequireSymbol || hasSymbol)
+}
+
+// passwordPolicy 描述密码策略,用于参数错误提示
+func passwordPolicy(cfg *settings.PasswordConfig) string {
+	if cfg == nil {
+		return "密码不能为空"
+	}
+	policy := fmt.Sprintf("长度至少%d个字符", cfg.MinLength)
+	if cfg.MaxLength > 0 {
+		policy = fmt.Sprintf("长度为%d-%d个字符", cfg.MinLength, cfg.MaxLength)
+	}
+	var required []string
+	if cfg.RequireUpper {
+		required = append(required, "大写字母")
+	}
+	if cfg.RequireLower {
+		required = append(required, "小写字母")
+	}
+	if cfg.RequireDigit {
+		required = append(required, "数字")
+	}
+	if cfg.RequireSymbol {
+		required = append(required, "特殊字符")
+	}
+	if len(required) > 0 {
+		policy += ",且必须包含" + strings.Join(required, "、")
+	}
+	return policy
+}
+
 // removeTopStruct 去除提示信息中的结构体名称
 func removeTopStruct(fields map[string]string) map[string]string {
 	res := map[string]string{}
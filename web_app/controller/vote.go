@@ -22,7 +22,8 @@ import (
 // @Success 200 {object} _Response "投票成功"
 // @Failure 400 {object} _Response "参数错误"
 // @Failure 401 {object} _Response "用户未登录"
-// @Failure 403 {object} _Response "重复投票"
+// @Failure 403 {object} _Response "重复投票、投票时间已过或邮箱未验证"
+// @Failure 404 {object} _Response "帖子不存在"
 // @Failure 500 {object} _Response "服务器繁忙"
 // @Router /api/v2/vote [post]
 func VoteForPostHandler(c *gin.Context) {
@@ -51,6 +52,41 @@ func VoteForPostHandler(c *gin.Context) {
 			ResponseError(c, CodeVoteRepeated)
 			return
 		}
+		if errors.Is(err, logic.ErrorVoteTimeExpired) {
+			ResponseError(c, CodeVoteTimeExpired)
+			return
+		}
+		if errors.Is(err, logic.ErrorPostNotExist) {
+			ResponseError(c, CodePostNotExists)
+			return
+		}
+		if errors.Is(err, logic.ErrorEmailNotVerified) {
+			ResponseError(c, CodeEmailNotVerified)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	// 返回响应
+	ResponseSuccess(c, nil)
+}
+
+// RebuildVoteNumHandler 重建帖子投票数量功能
+// @Summary 重建帖子投票数量
+// @Description 管理员按redis中的用户投票重新统计所有帖子的赞成票和反对票数量,已归档帖子从归档表恢复
+// @Tags 投票相关接口
+// @Produce json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Security	ApiKeyAuth
+// @Success 200 {object} _Response "重建成功"
+// @Failure 401 {object} _Response "用户未登录"
+// @Failure 403 {object} _Response "没有权限"
+// @Failure 500 {object} _Response "服务器繁忙"
+// @Router /admin/rebuild/vote/num [put]
+func RebuildVoteNumHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 业务处理
+	if err := logic.RebuildVoteNums(ctx); err != nil {
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"web_app/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateComment 创建新评论
+func CreateComment(ctx context.Context, comment *models.Comment) (err error) {
+	sqlStr := `insert into comment (comment_id,
+		post_id,
+		author_id,
+		parent_id,
+		content)
+		values(?,?,?,?,?)
+	`
+	_, err = db.ExecContext(ctx, sqlStr, comment.CommentID, comment.PostID, comment.AuthorID, comment.ParentID, comment.Content)
+	return err
+}
+
+// GetComment 通过id获得评论信息,已删除的评论视为不存在
+func GetComment(ctx context.Context, commentID int64) (comment *models.Comment, err error) {
+	sqlStr := `select 
+				comment_id, post_id, author_id, parent_id, content, create_time
+				from
+				comment
+				where comment_id = ? and status = ?
+	`
+	comment = new(models.Comment)
+	err = db.GetContext(ctx, comment, sqlStr, commentID, models.CommentStatusNormal)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrorCommentNotExist
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetCommentList 按评论id升序获得帖子下某条父评论的回复,从cursor开始(包含cursor)最多size条
+func GetCommentList(ctx context.Context, postID, parentID, cursor, size int64) (comments []*models.Comment, err error) {
+	sqlStr := `select 
+				comment_id, post_id, author_id, parent_id, content, create_time
+				from
+				comment
+				where post_id = ? and parent_id = ? and status = ? and comment_id >= ?
+				order by comment_id
+				limit ?
+	`
+	comments = make([]*models.Comment, 0, size)
+	err = db.SelectContext(ctx, &comments, sqlStr, postID, parentID, models.CommentStatusNormal, cursor, size)
+	return comments, err
+}
+
+// GetCommentNum 获得帖子下未删除的评论数量
+func GetCommentNum(ctx context.Context, postID int64) (num int64, err error) {
+	sqlStr := `select count(*) from comment where post_id = ? and status = ?`
+	err = db.GetContext(ctx, &num, sqlStr, postID, models.CommentStatusNormal)
+	return num, err
+}
+
+// GetCommentNums 批量获得帖子下未删除的评论数量,没有评论的帖子不在结果中
+func GetCommentNums(ctx context.Context, postIDs []int64) (nums map[int64]int64, err error) {
+	nums = make(map[int64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return nums, nil
+	}
+	sqlStr := `select post_id, count(*) as comment_num
+				from comment
+				where post_id in (?) and status = ?
+				group by post_id
+	`
+	query, args, err := sqlx.In(sqlStr, postIDs, models.CommentStatusNormal)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		PostID     int64 `db:"post_id"`
+		CommentNum int64 `db:"comment_num"`
+	}
+	if err = db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		nums[row.PostID] = row.CommentNum
+	}
+	return nums, nil
+}
+
+// GetReplyNums 批量获得评论的回复数量,没有回复的评论不在结果中
+func GetReplyNums(ctx context.Context, commentIDs []int64) (nums map[int64]int64, err error) {
+	nums = make(map[int64]int64, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return nums, nil
+	}
+	sqlStr := `select parent_id, count(*) as reply_num
+				from comment
+				where parent_id in (?) and status = ?
+				group by parent_id
+	`
+	query, args, err := sqlx.In(sqlStr, commentIDs, models.CommentStatusNormal)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ParentID int64 `db:"parent_id"`
+		ReplyNum int64 `db:"reply_num"`
+	}
+	if err = db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		nums[row.ParentID] = row.ReplyNum
+	}
+	return nums, nil
+}
@@ -34,10 +34,10 @@ func GetCommunityList() (communityList []*models.Community, err error) {
 	return communityList, err
 }
 
-// GetCommunityDetailList 从数据库中获得社区细节列表
+// GetCommunityDetailList 从数据库中获得未归档的社区细节列表
 func GetCommunityDetailList() (communityDetailList []*models.CommunityDetail, err error) {
-	sqlStr := `select community_id, community_name, introduction, create_time from community`
-	err = db.Select(&communityDetailList, sqlStr)
+	sqlStr := `select community_id, community_name, introduction, status, member_num, create_time from community where status = ?`
+	err = db.Select(&communityDetailList, sqlStr, models.CommunityStatusNormal)
 	return communityDetailList, err
 }
 
@@ -51,7 +51,7 @@ func GetCommunityIDs() (ids []int64, err error) {
 // GetCommunityDetail 通过id从数据库获得社区信息
 func GetCommunityDetail(communityID int64) (communityDetail *models.CommunityDetail, err error) {
 	sqlStr := `select 
-		community_id, community_name, introduction, create_time 
+		community_id, community_name, introduction, status, member_num, create_time 
 		from community 
 		where community_id = ?
 	`
@@ -64,3 +64,51 @@ func GetCommunityDetail(communityID int64) (communityDetail *models.CommunityDet
 	}
 	return communityDetail, nil
 }
+
+// CommunityNameExists 查看社区名称是否被其他社区使用
+func CommunityNameExists(ctx context.Context, communityID int64, communityName string) (err error) {
+	sqlStr := `select count(community_id) from community where community_name = ? and community_id != ?`
+	var count int64
+	if err = db.GetContext(ctx, &count, sqlStr, communityName, communityID); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorCommunityNameExist
+	}
+	return nil
+}
+
+// UpdateCommunity 更新社区名称和简介
+func UpdateCommunity(ctx context.Context, communityID int64, p *models.ParamUpdateCommunity) (err error) {
+	sqlStr := `update community set community_name = ?, introduction = ? where community_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, p.CommunityName, p.Introduction, communityID)
+	return err
+}
+
+// UpdateCommunityStatus 更新社区状态
+func UpdateCommunityStatus(ctx context.Context, communityID int64, status int8) (err error) {
+	sqlStr := `update community set status = ? where community_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, status, communityID)
+	return err
+}
+
+// GetArchivedCommunityIDs 从数据库中获得已归档社区的ids
+func GetArchivedCommunityIDs(ctx context.Context) (ids []int64, err error) {
+	sqlStr := `select community_id from community where status = ?`
+	err = db.SelectContext(ctx, &ids, sqlStr, models.CommunityStatusArchived)
+	return ids, err
+}
+
+// GetCommunityAdminList 从数据库中获得所有社区及其帖子数量
+func GetCommunityAdminList(ctx context.Context) (communityList []*models.ApiCommunityAdmin, err error) {
+	sqlStr := `select
+		c.community_id, c.community_name, c.introduction, c.status, c.member_num, c.create_time,
+		count(p.post_id) as post_num
+		from community c
+		left join post p on p.community_id = c.community_id and p.status = ?
+		group by c.community_id, c.community_name, c.introduction, c.status, c.member_num, c.create_time
+		order by c.community_id
+	`
+	err = db.SelectContext(ctx, &communityList, sqlStr, models.PostStatusNormal)
+	return communityList, err
+}
@@ -3,10 +3,13 @@ package mysql
 import "errors"
 
 var (
-	ErrorUsernameExist     = errors.New("用户名已存在")
-	ErrorUsernameNotFound  = errors.New("用户名不存在")
-	ErrorCommunityIDExist  = errors.New("社区已存在")
-	ErrorCommunityNotExist = errors.New("社区不存在")
-	ErrorPostNotExist      = errors.New("帖子不存在")
-	ErrorUserNotFound      = errors.New("用户不存在")
+	ErrorUsernameExist      = errors.New("用户名已存在")
+	ErrorUsernameNotFound   = errors.New("用户名不存在")
+	ErrorCommunityIDExist   = errors.New("社区已存在")
+	ErrorCommunityNotExist  = errors.New("社区不存在")
+	ErrorCommunityNameExist = errors.New("社区名称已存在")
+	ErrorPostNotExist       = errors.New("帖子不存在")
+	ErrorUserNotFound       = errors.New("用户不存在")
+	ErrorCommentNotExist    = errors.New("评论不存在")
+	ErrorEmailExist         = errors.New("邮箱已被使用")
 )
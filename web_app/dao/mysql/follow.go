@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+)
+
+// Follow 关注用户,已经关注时忽略
+func Follow(ctx context.Context, followerID, followeeID int64) (err error) {
+	sqlStr := `insert ignore into user_follow (follower_id, followee_id) values (?,?)`
+	_, err = db.ExecContext(ctx, sqlStr, followerID, followeeID)
+	return err
+}
+
+// Unfollow 取消关注用户
+func Unfollow(ctx context.Context, followerID, followeeID int64) (err error) {
+	sqlStr := `delete from user_follow where follower_id = ? and followee_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, followerID, followeeID)
+	return err
+}
+
+// GetFollowerNum 获得用户的粉丝数量
+func GetFollowerNum(ctx context.Context, userID int64) (num int64, err error) {
+	sqlStr := `select count(id) from user_follow where followee_id = ?`
+	err = db.GetContext(ctx, &num, sqlStr, userID)
+	return num, err
+}
+
+// GetFollowingNum 获得用户关注的人数
+func GetFollowingNum(ctx context.Context, userID int64) (num int64, err error) {
+	sqlStr := `select count(id) from user_follow where follower_id = ?`
+	err = db.GetContext(ctx, &num, sqlStr, userID)
+	return num, err
+}
+
+// GetFollowerIDs 获得用户的粉丝ids
+func GetFollowerIDs(ctx context.Context, userID int64) (ids []int64, err error) {
+	sqlStr := `select follower_id from user_follow where followee_id = ?`
+	err = db.SelectContext(ctx, &ids, sqlStr, userID)
+	return ids, err
+}
+
+// GetPopularFolloweeIDs 获得用户关注的人中粉丝数量超过threshold的用户ids
+func GetPopularFolloweeIDs(ctx context.Context, userID, threshold int64) (ids []int64, err error) {
+	sqlStr := `select f.followee_id
+		from user_follow f
+		join user_follow ff on ff.followee_id = f.followee_id
+		where f.follower_id = ?
+		group by f.followee_id
+		having count(ff.id) > ?
+	`
+	err = db.SelectContext(ctx, &ids, sqlStr, userID, threshold)
+	return ids, err
+}
@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"web_app/models"
+)
+
+// ApplyKarmaVote 在一个事务中将投票计入作者在社区中的karma
+// 按已计入的投票类型计算差值,同一投票重复消费时差值为0,karma不变
+func ApplyKarmaVote(ctx context.Context, authorID, communityID int64, vote *models.VotePost) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	var applied int8
+	sqlStr := `select vote_type from karma_vote where post_id = ? and user_id = ? for update`
+	err = tx.GetContext(ctx, &applied, sqlStr, vote.PostID, vote.UserID)
+	if errors.Is(err, sql.ErrNoRows) { // 该投票还没有计入karma
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	delta := int64(vote.VoteType - applied)
+	if delta == 0 {
+		return tx.Commit()
+	}
+	sqlStr = `insert into karma_vote (post_id, user_id, vote_type)
+				values (?,?,?)
+				on duplicate key update vote_type = values(vote_type)
+	`
+	if _, err = tx.ExecContext(ctx, sqlStr, vote.PostID, vote.UserID, vote.VoteType); err != nil {
+		return err
+	}
+	sqlStr = `insert into user_karma (user_id, community_id, karma)
+				values (?,?,?)
+				on duplicate key update karma = karma + values(karma)
+	`
+	if _, err = tx.ExecContext(ctx, sqlStr, authorID, communityID, delta); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetUserKarma 获得作者在各社区中的karma
+func GetUserKarma(ctx context.Context, userID int64) (karmas []*models.UserKarma, err error) {
+	sqlStr := `select user_id, community_id, karma from user_karma where user_id = ?`
+	err = db.SelectContext(ctx, &karmas, sqlStr, userID)
+	return karmas, err
+}
+
+// GetUserKarmas 获得所有作者在各社区中的karma
+func GetUserKarmas(ctx context.Context) (karmas []*models.UserKarma, err error) {
+	sqlStr := `select user_id, community_id, karma from user_karma`
+	err = db.SelectContext(ctx, &karmas, sqlStr)
+	return karmas, err
+}
+
+// CountUserKarmas 从投票表统计每个作者在每个社区中的帖子获得的赞成票减反对票,帖子删除后已获得的karma仍保留
+func CountUserKarmas(ctx context.Context) (karmas []*models.UserKarma, err error) {
+	sqlStr := `select p.author_id as user_id, p.community_id, sum(v.vote_type) as karma
+		from vote_post v
+		join post p on p.post_id = v.post_id
+		group by p.author_id, p.community_id
+	`
+	err = db.SelectContext(ctx, &karmas, sqlStr)
+	return karmas, err
+}
+
+// ReplaceUserKarmas 在一个事务中用重新统计的结果替换karma表,并将已计入karma的投票重置为投票表中的记录
+func ReplaceUserKarmas(ctx context.Context, karmas []*models.UserKarma) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.ExecContext(ctx, `delete from user_karma`); err != nil {
+		return err
+	}
+	sqlStr := `insert into user_karma (user_id, community_id, karma) values (?,?,?)`
+	for _, karma := range karmas {
+		if _, err = tx.ExecContext(ctx, sqlStr, karma.UserID, karma.CommunityID, karma.Karma); err != nil {
+			return err
+		}
+	}
+	// 投票表中还没有的投票由karma消费者在之后计入
+	if _, err = tx.ExecContext(ctx, `delete from karma_vote`); err != nil {
+		return err
+	}
+	sqlStr = `insert into karma_vote (post_id, user_id, vote_type)
+		select v.post_id, v.user_id, v.vote_type
+		from vote_post v
+		join post p on p.post_id = v.post_id
+	`
+	if _, err = tx.ExecContext(ctx, sqlStr); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"web_app/models"
+)
+
+// JoinCommunity 用户加入社区并增加社区成员数量,已经是成员时忽略
+func JoinCommunity(ctx context.Context, communityID, userID int64) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `insert ignore into community_member (community_id, user_id) values (?,?)`
+	result, err := tx.ExecContext(ctx, sqlStr, communityID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		sqlStr = `update community set member_num = member_num + 1 where community_id = ?`
+		if _, err = tx.ExecContext(ctx, sqlStr, communityID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LeaveCommunity 用户退出社区并减少社区成员数量,不是成员时忽略
+func LeaveCommunity(ctx context.Context, communityID, userID int64) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `delete from community_member where community_id = ? and user_id = ?`
+	result, err := tx.ExecContext(ctx, sqlStr, communityID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		sqlStr = `update community set member_num = member_num - 1 where community_id = ? and member_num > 0`
+		if _, err = tx.ExecContext(ctx, sqlStr, communityID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetJoinedCommunityList 获得用户加入的未归档社区,按加入时间倒序
+func GetJoinedCommunityList(ctx context.Context, userID int64) (communityList []*models.Community, err error) {
+	sqlStr := `select c.community_id, c.community_name
+		from community_member m
+		join community c on c.community_id = m.community_id
+		where m.user_id = ? and c.status = ?
+		order by m.id desc
+	`
+	err = db.SelectContext(ctx, &communityList, sqlStr, userID, models.CommunityStatusNormal)
+	return communityList, err
+}
+
+// GetJoinedCommunityIDs 获得用户加入的未归档社区ids
+func GetJoinedCommunityIDs(ctx context.Context, userID int64) (ids []int64, err error) {
+	sqlStr := `select m.community_id
+		from community_member m
+		join community c on c.community_id = m.community_id
+		where m.user_id = ? and c.status = ?
+	`
+	err = db.SelectContext(ctx, &ids, sqlStr, userID, models.CommunityStatusNormal)
+	return ids, err
+}
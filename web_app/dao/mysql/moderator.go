@@ -0,0 +1,26 @@
+package mysql
+
+import (
+	"context"
+)
+
+// GetModeratedCommunityIDs 获得用户担任版主的社区ids
+func GetModeratedCommunityIDs(ctx context.Context, userID int64) (ids []int64, err error) {
+	sqlStr := `select community_id from community_moderator where user_id = ?`
+	err = db.SelectContext(ctx, &ids, sqlStr, userID)
+	return ids, err
+}
+
+// CreateModerator 指派用户为社区版主,已经是版主时忽略
+func CreateModerator(ctx context.Context, communityID, userID int64) (err error) {
+	sqlStr := `insert ignore into community_moderator (community_id, user_id) values (?,?)`
+	_, err = db.ExecContext(ctx, sqlStr, communityID, userID)
+	return err
+}
+
+// DeleteModerator 取消用户的社区版主身份
+func DeleteModerator(ctx context.Context, communityID, userID int64) (err error) {
+	sqlStr := `delete from community_moderator where community_id = ? and user_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, communityID, userID)
+	return err
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"web_app/models"
+
+	"github.com/jmoiron/sqlx"
 )
 
 // CreatePost 创建新帖子
@@ -20,17 +22,36 @@ func CreatePost(ctx context.Context, post *models.Post) (err error) {
 	return err
 }
 
-// GetPostIDs 获取所有帖子的id
+// GetPostIDs 获取所有未删除帖子的id
 func GetPostIDs() (ids []int64, err error) {
-	sqlStr := `select post_id from post`
-	err = db.Select(&ids, sqlStr)
+	sqlStr := `select post_id from post where status = ?`
+	err = db.Select(&ids, sqlStr, models.PostStatusNormal)
 	return ids, err
 }
 
-// GetPost 通过id获得帖子信息
+// GetPost 通过id获得帖子信息,已删除的帖子视为不存在
 func GetPost(ctx context.Context, postID int64) (post *models.Post, err error) {
 	sqlStr := `select 
-				post_id, author_id, community_id, title, content, create_time
+				post_id, author_id, community_id, status, title, content, create_time
+				from
+				post
+				where post_id = ? and status = ?
+	`
+	post = new(models.Post)
+	err = db.GetContext(ctx, post, sqlStr, postID, models.PostStatusNormal)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrorPostNotExist
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetPostIncludeDeleted 通过id获得帖子信息,已删除的帖子也会返回
+func GetPostIncludeDeleted(ctx context.Context, postID int64) (post *models.Post, err error) {
+	sqlStr := `select 
+				post_id, author_id, community_id, status, title, content, create_time
 				from
 				post
 				where post_id = ?
@@ -45,3 +66,49 @@ func GetPost(ctx context.Context, postID int64) (post *models.Post, err error) {
 	}
 	return post, nil
 }
+
+// UpdatePost 更新帖子标题和内容
+func UpdatePost(ctx context.Context, post *models.Post) (err error) {
+	sqlStr := `update post
+				set title = ?, content = ?
+				where post_id = ? and status = ?
+	`
+	_, err = db.ExecContext(ctx, sqlStr, post.Title, post.Content, post.PostID, models.PostStatusNormal)
+	return err
+}
+
+// DeletePost 软删除帖子,将帖子状态设为已删除
+func DeletePost(ctx context.Context, postID int64) (err error) {
+	sqlStr := `update post
+				set status = ?
+				where post_id = ?
+	`
+	_, err = db.ExecContext(ctx, sqlStr, models.PostStatusDeleted, postID)
+	return err
+}
+
+// GetPosts 通过id批量获得帖子信息,已删除的帖子不在结果中
+func GetPosts(ctx context.Context, postIDs []int64) (posts []*models.Post, err error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+	sqlStr := `select 
+				post_id, author_id, community_id, status, title, content, create_time
+				from
+				post
+				where post_id in (?) and status = ?
+	`
+	query, args, err := sqlx.In(sqlStr, postIDs, models.PostStatusNormal)
+	if err != nil {
+		return nil, err
+	}
+	err = db.SelectContext(ctx, &posts, db.Rebind(query), args...)
+	return posts, err
+}
+
+// GetAuthorPosts 获得作者发布的未删除帖子的id和发帖时间
+func GetAuthorPosts(ctx context.Context, authorID int64) (posts []*models.Post, err error) {
+	sqlStr := `select post_id, create_time from post where author_id = ? and status = ?`
+	err = db.SelectContext(ctx, &posts, sqlStr, authorID, models.PostStatusNormal)
+	return posts, err
+}
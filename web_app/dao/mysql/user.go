@@ -4,9 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"web_app/models"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 )
 
+// errNumDuplicateEntry MySQL唯一索引冲突的错误码
+const errNumDuplicateEntry = 1062
+
+// duplicateKeyError 唯一索引冲突时返回索引对应的错误,其他错误原样返回
+// 先查询再写入的检查可能被并发请求绕过,以唯一索引为准
+func duplicateKeyError(err error, keyErrors map[string]error) error {
+	var mysqlErr *mysqldriver.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != errNumDuplicateEntry {
+		return err
+	}
+	// MySQL 8的错误信息中索引名带有表名前缀,如 for key 'user.idx_email'
+	for key, keyErr := range keyErrors {
+		if strings.HasSuffix(mysqlErr.Message, "'"+key+"'") || strings.HasSuffix(mysqlErr.Message, "."+key+"'") {
+			return keyErr
+		}
+	}
+	return err
+}
+
 // UsernameExists 判断用户名是否存在
 func UsernameExists(ctx context.Context, username string) (err error) {
 	sqlStr := `select count(user_id) from user where username=?`
@@ -20,16 +43,68 @@ func UsernameExists(ctx context.Context, username string) (err error) {
 	return
 }
 
-// CreateUser 创建用户信息
+// CreateUser 创建用户信息,用户名或邮箱已被使用时返回 ErrorUsernameExist 或 ErrorEmailExist
 func CreateUser(user *models.User) (err error) {
-	sqlStr := `INSERT INTO user (user_id, username, password) VALUES (?,?,?)`
-	_, err = db.Exec(sqlStr, user.UserID, user.Username, user.Password)
-	return err
+	sqlStr := `INSERT INTO user (user_id, username, password, email) VALUES (?,?,?,nullif(?, ''))`
+	_, err = db.Exec(sqlStr, user.UserID, user.Username, user.Password, user.Email)
+	return duplicateKeyError(err, map[string]error{
+		"idx_username": ErrorUsernameExist,
+		"idx_email":    ErrorEmailExist,
+	})
+}
+
+// EmailExists 查看邮箱是否被其他用户使用
+func EmailExists(ctx context.Context, userID int64, email string) (err error) {
+	sqlStr := `select count(user_id) from user where email = ? and user_id != ?`
+	var count int64
+	if err = db.GetContext(ctx, &count, sqlStr, email, userID); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorEmailExist
+	}
+	return nil
+}
+
+// SetEmailVerified 将用户的邮箱标记为已验证,邮箱已被修改时不更新并返回false
+func SetEmailVerified(ctx context.Context, userID int64, email string) (ok bool, err error) {
+	sqlStr := `update user set email_verified = 1 where user_id = ? and email = ?`
+	result, err := db.ExecContext(ctx, sqlStr, userID, email)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+	// 邮箱已经验证过时RowsAffected为0,需要再查询一次
+	var verified bool
+	sqlStr = `select email_verified from user where user_id = ? and email = ?`
+	if err = db.GetContext(ctx, &verified, sqlStr, userID, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return verified, nil
+}
+
+// GetEmailVerified 获得用户的邮箱是否已验证
+func GetEmailVerified(ctx context.Context, userID int64) (verified bool, err error) {
+	sqlStr := `select email_verified from user where user_id = ?`
+	err = db.GetContext(ctx, &verified, sqlStr, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrorUserNotFound
+	}
+	return verified, err
 }
 
 // GetUserByUsername 通过用户名获得用户信息
 func GetUserByUsername(ctx context.Context, username string, user *models.User) (err error) {
-	sqlStr := `SELECT user_id, username, password FROM user WHERE username = ?`
+	sqlStr := `SELECT user_id, username, password, role FROM user WHERE username = ?`
 	if err = db.GetContext(ctx, user, sqlStr, username); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrorUsernameNotFound
@@ -50,3 +125,99 @@ func GetUserName(ctx context.Context, userID int64) (username string, err error)
 	}
 	return username, nil
 }
+
+// GetUserByID 通过用户id获得用户名和角色,不查询密码
+func GetUserByID(ctx context.Context, userID int64) (user *models.User, err error) {
+	user = new(models.User)
+	sqlStr := `select user_id, username, role from user where user_id = ?`
+	err = db.GetContext(ctx, user, sqlStr, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrorUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserRole 通过用户id获得用户角色
+func GetUserRole(ctx context.Context, userID int64) (role int8, err error) {
+	sqlStr := `select role from user where user_id = ?`
+	err = db.GetContext(ctx, &role, sqlStr, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrorUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return role, nil
+}
+
+// UpdateUserRole 更新用户角色
+func UpdateUserRole(ctx context.Context, userID int64, role int8) (err error) {
+	sqlStr := `update user set role = ? where user_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, role, userID)
+	return err
+}
+
+// GetUserNames 通过用户id批量获得用户名,不存在的用户不在结果中
+func GetUserNames(ctx context.Context, userIDs []int64) (names map[int64]string, err error) {
+	names = make(map[int64]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+	query, args, err := sqlx.In(`select user_id, username from user where user_id in (?)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	var users []*models.User
+	if err = db.SelectContext(ctx, &users, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		names[user.UserID] = user.Username
+	}
+	return names, nil
+}
+
+// GetUserProfile 通过用户id获得用户的个人资料
+func GetUserProfile(ctx context.Context, userID int64) (profile *models.UserProfile, err error) {
+	sqlStr := `select user_id, username, coalesce(email, '') as email, email_verified, gender, bio, avatar_url from user where user_id = ?`
+	profile = new(models.UserProfile)
+	if err = db.GetContext(ctx, profile, sqlStr, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrorUserNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateUserProfile 更新用户的邮箱、性别、简介和头像,邮箱为空时设为null
+// 邮箱变更时重置为未验证,email_verified需要在email之前赋值
+// 邮箱已被其他用户使用时返回 ErrorEmailExist
+func UpdateUserProfile(ctx context.Context, userID int64, p *models.ParamUpdateProfile) (err error) {
+	sqlStr := `update user set
+		email_verified = if(email <=> nullif(?, ''), email_verified, 0),
+		email = nullif(?, ''), gender = ?, bio = ?, avatar_url = ?
+		where user_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, p.Email, p.Email, p.Gender, p.Bio, p.AvatarURL, userID)
+	return duplicateKeyError(err, map[string]error{"idx_email": ErrorEmailExist})
+}
+
+// GetUserPassword 通过用户id获得加密后的密码
+func GetUserPassword(ctx context.Context, userID int64) (password string, err error) {
+	sqlStr := `select password from user where user_id = ?`
+	err = db.GetContext(ctx, &password, sqlStr, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrorUserNotFound
+	}
+	return password, err
+}
+
+// UpdateUserPassword 更新用户加密后的密码
+func UpdateUserPassword(ctx context.Context, userID int64, password string) (err error) {
+	sqlStr := `update user set password = ? where user_id = ?`
+	_, err = db.ExecContext(ctx, sqlStr, password, userID)
+	return err
+}
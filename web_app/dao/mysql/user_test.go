@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestDuplicateKeyError(t *testing.T) {
+	keyErrors := map[string]error{
+		"idx_username": ErrorUsernameExist,
+		"idx_email":    ErrorEmailExist,
+	}
+	otherErr := errors.New("other")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"mysql 5.7 email", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'idx_email'"}, ErrorEmailExist},
+		{"mysql 8 email", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'user.idx_email'"}, ErrorEmailExist},
+		{"username", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'bob' for key 'user.idx_username'"}, ErrorUsernameExist},
+		{"other key", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'user.idx_user_id'"}, nil},
+		{"other mysql error", &mysqldriver.MySQLError{Number: 1064, Message: "idx_email'"}, nil},
+		{"other error", otherErr, otherErr},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := duplicateKeyError(tt.err, keyErrors)
+			want := tt.want
+			if want == nil {
+				want = tt.err
+			}
+			if got != want {
+				t.Errorf("duplicateKeyError() = %v, want %v", got, want)
+			}
+		})
+	}
+}
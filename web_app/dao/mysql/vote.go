@@ -34,3 +34,40 @@ func UpdateVotePost(ctx context.Context, msg *models.VotePost) (err error) {
 	_, err = db.Exec(sqlStr, msg.VoteType, msg.PostID, msg.UserID)
 	return err
 }
+
+// GetArchivedVoteNums 获得所有已归档帖子的最终赞成票和反对票数量
+func GetArchivedVoteNums(ctx context.Context) (voteNums []*models.VoteNum, err error) {
+	sqlStr := `select post_id, up_num, down_num from post_vote_archive`
+	err = db.SelectContext(ctx, &voteNums, sqlStr)
+	return voteNums, err
+}
+
+// ArchivePostVotes 在一个事务中保存帖子的最终投票结果,并将redis中的用户投票写入投票表
+func ArchivePostVotes(ctx context.Context, archive *models.PostVoteArchive, votes []*models.VotePost) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `insert into post_vote_archive (post_id, score, up_num, down_num)
+				values (?,?,?,?)
+				on duplicate key update score = values(score), up_num = values(up_num), down_num = values(down_num)
+	`
+	if _, err = tx.ExecContext(ctx, sqlStr, archive.PostID, archive.Score, archive.UpNum, archive.DownNum); err != nil {
+		return err
+	}
+	sqlStr = `insert into vote_post (post_id, user_id, vote_type)
+				values (?,?,?)
+				on duplicate key update vote_type = values(vote_type)
+	`
+	for _, vote := range votes {
+		if _, err = tx.ExecContext(ctx, sqlStr, vote.PostID, vote.UserID, vote.VoteType); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
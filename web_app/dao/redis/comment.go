@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	CommentNumExpireTime = 24 * time.Hour // 评论数量缓存过期时间设为一天
+)
+
+// commentScript 评论数量缓存存在时自增,帖子未超出投票期限时更新帖子分数
+// KEYS[1] lightning:post:<post_id>:comment_num  KEYS[2] lightning:post:score  KEYS[3] lightning:post:time
+// ARGV[1] 每条评论分数  ARGV[2] post_id  ARGV[3] 允许改变分数的最早发帖时间,0表示不限制
+// 返回{评论数量,是否超出期限},评论数量缓存不存在时评论数量为-1,避免在计数未加载时写入错误的值
+var commentScript = redis.NewScript(`
+local num = -1
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	num = redis.call("INCR", KEYS[1])
+end
+local deadline = tonumber(ARGV[3])
+if deadline > 0 then
+	local createTime = redis.call("ZSCORE", KEYS[3], ARGV[2])
+	if createTime and tonumber(createTime) < deadline then
+		return {num, 1}
+	end
+end
+if redis.call("ZSCORE", KEYS[2], ARGV[2]) then
+	redis.call("ZINCRBY", KEYS[2], ARGV[1], ARGV[2])
+end
+return {num, 0}
+`)
+
+// GetCommentNum 获得帖子的评论数量,缓存中没有时返回 ErrorDataNotFound
+func GetCommentNum(ctx context.Context, postID int64) (num int64, err error) {
+	key := GetKeyPostCommentNum(postID)
+	num, err = rdb.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, ErrorDataNotFound
+	}
+	return num, err
+}
+
+// GetCommentNums 批量获得帖子的评论数量,缓存中没有的帖子不在结果中
+func GetCommentNums(ctx context.Context, postIDs []int64) (nums map[int64]int64, err error) {
+	nums = make(map[int64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return nums, nil
+	}
+	keys := make([]string, 0, len(postIDs))
+	for _, postID := range postIDs {
+		keys = append(keys, GetKeyPostCommentNum(postID))
+	}
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		numStr, ok := value.(string)
+		if !ok { //缓存未命中
+			continue
+		}
+		num, err := strconv.ParseInt(numStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		nums[postIDs[i]] = num
+	}
+	return nums, nil
+}
+
+// SetCommentNums 使用管道批量设置帖子的评论数量缓存,缓存已存在时不覆盖
+func SetCommentNums(ctx context.Context, nums map[int64]int64) (err error) {
+	if len(nums) == 0 {
+		return nil
+	}
+	pipe := rdb.Pipeline()
+	for postID, num := range nums {
+		pipe.SetNX(ctx, GetKeyPostCommentNum(postID), num, CommentNumExpireTime)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SetCommentNum 设置帖子的评论数量缓存,缓存已存在时不覆盖
+func SetCommentNum(ctx context.Context, postID, num int64) (err error) {
+	key := GetKeyPostCommentNum(postID)
+	return rdb.SetNX(ctx, key, num, CommentNumExpireTime).Err()
+}
+
+// CreateComment 帖子新增评论时更新评论数量和帖子分数
+// 帖子创建时间早于deadline时只更新评论数量,expired为true;帖子不在分数ZSet中(已删除)时不更新分数
+// 评论数量缓存不存在时返回 ErrorDataNotFound,由调用方从数据库加载
+func CreateComment(ctx context.Context, changeScore int, deadline int64, postID int64) (expired bool, err error) {
+	keys := []string{
+		GetKeyPostCommentNum(postID),
+		GetKeyPostScoreZSet(),
+		GetKeyPostTimeZSet(),
+	}
+	result, err := commentScript.Run(ctx, rdb, keys, changeScore, postID, deadline).Int64Slice()
+	if err != nil {
+		return false, err
+	}
+	if len(result) != 2 {
+		return false, ErrorInvalidDataFormat
+	}
+	expired = result[1] == 1
+	if result[0] < 0 {
+		return expired, ErrorDataNotFound
+	}
+	return expired, nil
+}
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestCreateComment 评论数量已缓存时自增,未缓存时返回ErrorDataNotFound且不写入,已删除的帖子不写入分数
+func TestCreateComment(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.ZAdd(GetKeyPostScoreZSet(), 100, "1")
+	if _, err := CreateComment(ctx, 216, 0, 1); err != ErrorDataNotFound {
+		t.Fatalf("CreateComment(not loaded) err = %v, want %v", err, ErrorDataNotFound)
+	}
+	if mr.Exists(GetKeyPostCommentNum(1)) {
+		t.Error("comment num should not be created before it is loaded")
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), "1"); score != 316 {
+		t.Errorf("score = %v, want 316", score)
+	}
+
+	// 从数据库加载后的评论数量不会覆盖已有的缓存
+	if err := SetCommentNum(ctx, 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetCommentNum(ctx, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateComment(ctx, 216, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if num, err := GetCommentNum(ctx, 1); err != nil || num != 6 {
+		t.Errorf("GetCommentNum = %d, %v, want 6, nil", num, err)
+	}
+
+	// 帖子已删除,不重新加入分数ZSet
+	mr.Set(GetKeyPostCommentNum(2), "1")
+	if _, err := CreateComment(ctx, 216, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(GetKeyPostScoreZSet()); len(members) != 1 || members[0] != "1" {
+		t.Errorf("score members = %v, want [1]", members)
+	}
+}
+
+// TestCreateCommentExpired 超出投票期限的帖子只增加评论数量,不改变分数
+func TestCreateCommentExpired(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.ZAdd(GetKeyPostTimeZSet(), 100, "1")
+	mr.ZAdd(GetKeyPostScoreZSet(), 100, "1")
+	mr.ZAdd(GetKeyPostTimeZSet(), 300, "2")
+	mr.ZAdd(GetKeyPostScoreZSet(), 300, "2")
+	mr.Set(GetKeyPostCommentNum(1), "5")
+
+	expired, err := CreateComment(ctx, 216, 200, 1)
+	if err != nil || !expired {
+		t.Fatalf("CreateComment(expired post) = %v, %v, want true, nil", expired, err)
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), "1"); score != 100 {
+		t.Errorf("expired post score = %v, want 100", score)
+	}
+	if num, _ := mr.Get(GetKeyPostCommentNum(1)); num != "6" {
+		t.Errorf("comment num = %s, want 6", num)
+	}
+
+	expired, err = CreateComment(ctx, 216, 200, 2)
+	if err != ErrorDataNotFound || expired {
+		t.Fatalf("CreateComment(active post) = %v, %v, want false, %v", expired, err, ErrorDataNotFound)
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), "2"); score != 516 {
+		t.Errorf("active post score = %v, want 516", score)
+	}
+	if mr.Exists(GetKeyPostCommentNum(2)) {
+		t.Error("comment num should not be created before it is loaded")
+	}
+}
+
+// TestGetCommentNums 批量获得评论数量,缓存中没有的帖子不在结果中
+func TestGetCommentNums(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.Set(GetKeyPostCommentNum(1), "3")
+	mr.Set(GetKeyPostCommentNum(2), "0")
+	nums, err := GetCommentNums(ctx, []int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int64{1: 3, 2: 0}; !reflect.DeepEqual(nums, want) {
+		t.Errorf("GetCommentNums = %v, want %v", nums, want)
+	}
+}
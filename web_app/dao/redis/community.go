@@ -89,14 +89,53 @@ func CreateCommunityDetail(ctx context.Context, community *models.CommunityDetai
 		"community_id", community.CommunityID,
 		"community_name", community.CommunityName,
 		"introduction", community.Introduction,
+		"status", community.Status,
+		"member_num", community.MemberNum,
 		"create_time", community.CreateTime,
 	)
-	// 存社区ID ZSet存储方式
+	// 存社区ID ZSet存储方式,归档的社区不出现在社区列表中,并记录在已归档社区Set中
 	key = GetKeyCommunityIDsZSet()
-	pipe.ZAdd(ctx, key, &redis.Z{
-		Score:  float64(community.CreateTime.Unix()),
-		Member: community.CommunityID,
-	})
+	if community.Status == models.CommunityStatusArchived {
+		pipe.ZRem(ctx, key, strconv.FormatInt(community.CommunityID, 10))
+		pipe.SAdd(ctx, GetKeyCommunityArchivedSet(), community.CommunityID)
+	} else {
+		pipe.ZAdd(ctx, key, &redis.Z{
+			Score:  float64(community.CreateTime.Unix()),
+			Member: community.CommunityID,
+		})
+		pipe.SRem(ctx, GetKeyCommunityArchivedSet(), strconv.FormatInt(community.CommunityID, 10))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeleteCommunity 删除社区信息 Key lightning:community:<community_id> ; lightning:community:list
+func DeleteCommunity(ctx context.Context, communityID int64) (err error) {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, GetKeyCommunityHash(communityID))
+	pipe.ZRem(ctx, GetKeyCommunityIDsZSet(), strconv.FormatInt(communityID, 10))
+	pipe.SRem(ctx, GetKeyCommunityArchivedSet(), strconv.FormatInt(communityID, 10))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SetArchivedCommunities 用数据库中的已归档社区覆盖已归档社区Set,并删除按旧的已归档社区合并的全站帖子列表
+func SetArchivedCommunities(ctx context.Context, communityIDs []int64) (err error) {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, GetKeyCommunityArchivedSet())
+	if len(communityIDs) > 0 {
+		members := make([]interface{}, 0, len(communityIDs))
+		for _, communityID := range communityIDs {
+			members = append(members, communityID)
+		}
+		pipe.SAdd(ctx, GetKeyCommunityArchivedSet(), members...)
+	}
+	activeKeys := make([]string, 0, len(rankNames)+1)
+	activeKeys = append(activeKeys, GetKeyPostActiveZSet(models.OrderTime))
+	for _, name := range rankNames {
+		activeKeys = append(activeKeys, GetKeyPostActiveZSet(name))
+	}
+	pipe.Del(ctx, activeKeys...)
 	_, err = pipe.Exec(ctx)
 	return err
 }
@@ -111,6 +150,40 @@ func GetCommunityDetail(ctx context.Context, key string) (community *models.Comm
 		zap.L().Error("GetCommunityDetail failed", zap.Error(err))
 		return nil, err
 	}
+	return parseCommunityDetail(data)
+}
+
+// GetCommunityDetails 使用管道批量获取社区信息,缓存中没有的社区不在结果中
+func GetCommunityDetails(ctx context.Context, communityIDs []int64) (communities map[int64]*models.CommunityDetail, err error) {
+	communities = make(map[int64]*models.CommunityDetail, len(communityIDs))
+	if len(communityIDs) == 0 {
+		return communities, nil
+	}
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, 0, len(communityIDs))
+	for _, communityID := range communityIDs {
+		cmds = append(cmds, pipe.HGetAll(ctx, GetKeyCommunityHash(communityID)))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.Exec HGetAll communities failed", zap.Error(err))
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 { //缓存未命中
+			continue
+		}
+		community, err := parseCommunityDetail(data)
+		if err != nil {
+			continue
+		}
+		communities[communityIDs[i]] = community
+	}
+	return communities, nil
+}
+
+// parseCommunityDetail 将社区Hash解析为社区结构体
+func parseCommunityDetail(data map[string]string) (community *models.CommunityDetail, err error) {
 	idStr := data["community_id"]
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -123,10 +196,31 @@ func GetCommunityDetail(ctx context.Context, key string) (community *models.Comm
 		zap.L().Error("createTimeStr time.Parse failed failed", zap.Error(err))
 		return nil, err
 	}
+	// 旧缓存中可能没有status字段,默认为正常社区
+	status := models.CommunityStatusNormal
+	if statusStr, ok := data["status"]; ok {
+		parsedStatus, err := strconv.ParseInt(statusStr, 10, 8)
+		if err != nil {
+			zap.L().Error("strconv.ParseInt(statusStr,10,8) failed", zap.Error(err))
+			return nil, err
+		}
+		status = int8(parsedStatus)
+	}
+	// 旧缓存中可能没有member_num字段,默认为0
+	var memberNum int64
+	if memberNumStr, ok := data["member_num"]; ok {
+		memberNum, err = strconv.ParseInt(memberNumStr, 10, 64)
+		if err != nil {
+			zap.L().Error("strconv.ParseInt(memberNumStr,10,64) failed", zap.Error(err))
+			return nil, err
+		}
+	}
 	community = &models.CommunityDetail{
 		CommunityID:   id,
 		CommunityName: data["community_name"],
 		Introduction:  data["introduction"],
+		Status:        status,
+		MemberNum:     memberNum,
 		CreateTime:    createTime,
 	}
 	return community, nil
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+	"web_app/models"
+)
+
+// TestCreateCommunityDetailArchived 归档社区时保留社区信息,但从社区列表中移除
+func TestCreateCommunityDetailArchived(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	community := &models.CommunityDetail{
+		CommunityID:   1,
+		CommunityName: "go",
+		Introduction:  "golang",
+		Status:        models.CommunityStatusNormal,
+		CreateTime:    time.Unix(1700000000, 0).UTC(),
+	}
+	if err := CreateCommunityDetail(ctx, community); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(GetKeyCommunityIDsZSet()); len(members) != 1 || members[0] != "1" {
+		t.Fatalf("community list = %v, want [1]", members)
+	}
+
+	community.Status = models.CommunityStatusArchived
+	if err := CreateCommunityDetail(ctx, community); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(GetKeyCommunityIDsZSet()) {
+		t.Error("archived community should be removed from the community list")
+	}
+	got, err := GetCommunityDetail(ctx, GetKeyCommunityHash(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.CommunityStatusArchived || got.CommunityName != "go" || !got.CreateTime.Equal(community.CreateTime) {
+		t.Errorf("GetCommunityDetail = %+v, want %+v", got, community)
+	}
+}
+
+// TestGetCommunityDetailDefaultStatus 旧缓存中没有status字段时默认为正常社区
+func TestGetCommunityDetailDefaultStatus(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	key := GetKeyCommunityHash(2)
+	mr.HSet(key, "community_id", "2", "community_name", "rust", "create_time", "2023-11-14T22:13:20Z")
+	got, err := GetCommunityDetail(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.CommunityStatusNormal {
+		t.Errorf("status = %d, want %d", got.Status, models.CommunityStatusNormal)
+	}
+
+	if _, err := GetCommunityDetail(ctx, GetKeyCommunityHash(3)); err != ErrorDataNotFound {
+		t.Errorf("GetCommunityDetail(missing) err = %v, want %v", err, ErrorDataNotFound)
+	}
+}
@@ -3,8 +3,11 @@ package redis
 import "errors"
 
 var (
-	ErrorRefreshTokenNotFound = errors.New("RefreshToken未找到")
-	ErrorInvalidDataFormat    = errors.New("获取的数据格式不正确")
-	ErrorParseDataFailed      = errors.New("解析数据失败")
-	ErrorDataNotFound         = errors.New("未找到数据")
+	ErrorSessionNotFound     = errors.New("登录会话未找到")
+	ErrorRefreshTokenRotated = errors.New("RefreshToken已被轮换")
+	ErrorInvalidDataFormat   = errors.New("获取的数据格式不正确")
+	ErrorParseDataFailed     = errors.New("解析数据失败")
+	ErrorDataNotFound        = errors.New("未找到数据")
+	ErrorVoteTimeExpired     = errors.New("投票时间已过")
+	ErrorPostNotExist        = errors.New("帖子不存在")
 )
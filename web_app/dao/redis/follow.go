@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"web_app/models"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const TimelineMergedExpireTime = 60 * time.Second // 合并后的时间线过期时间设为一分钟
+
+// PushTimeline 将新帖子推送到粉丝的时间线,每条时间线只保留最新的maxLen篇帖子
+func PushTimeline(ctx context.Context, post *models.Post, followerIDs []int64, maxLen int64) (err error) {
+	if len(followerIDs) == 0 {
+		return nil
+	}
+	z := &redis.Z{
+		Score:  float64(post.CreatTime.Unix()),
+		Member: post.PostID,
+	}
+	pipe := rdb.Pipeline()
+	for _, followerID := range followerIDs {
+		key := GetKeyUserTimelineZSet(followerID)
+		pipe.ZAdd(ctx, key, z)
+		pipe.ZRemRangeByRank(ctx, key, 0, -maxLen-1)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RemoveFromTimelines 帖子被删除后将其从粉丝的时间线中移除,并删除粉丝合并的时间线
+func RemoveFromTimelines(ctx context.Context, postID int64, followerIDs []int64) (err error) {
+	if len(followerIDs) == 0 {
+		return nil
+	}
+	member := strconv.FormatInt(postID, 10)
+	pipe := rdb.Pipeline()
+	for _, followerID := range followerIDs {
+		pipe.ZRem(ctx, GetKeyUserTimelineZSet(followerID), member)
+		pipe.Del(ctx, GetKeyUserTimelineMergedZSet(followerID))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// FollowAuthor 关注作者后将作者已发布的帖子合并到用户的时间线
+func FollowAuthor(ctx context.Context, userID, authorID int64, maxLen int64) (err error) {
+	key := GetKeyUserTimelineZSet(userID)
+	pipe := rdb.TxPipeline()
+	pipe.ZUnionStore(ctx, key, &redis.ZStore{
+		Keys:      []string{key, GetKeyUserPostsZSet(authorID)},
+		Aggregate: "MAX",
+	})
+	pipe.ZRemRangeByRank(ctx, key, 0, -maxLen-1)
+	pipe.Del(ctx, GetKeyUserTimelineMergedZSet(userID))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// UnfollowAuthor 取消关注作者后将作者的帖子从用户的时间线中移除
+func UnfollowAuthor(ctx context.Context, userID, authorID int64) (err error) {
+	key := GetKeyUserTimelineZSet(userID)
+	pipe := rdb.TxPipeline()
+	pipe.ZDiffStore(ctx, key, key, GetKeyUserPostsZSet(authorID))
+	pipe.Del(ctx, GetKeyUserTimelineMergedZSet(userID))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetTimelineKey 获得时间线使用的ZSet,没有关注高粉丝作者时直接使用推送的时间线
+// 否则将推送的时间线与高粉丝作者发布的帖子合并,合并结果缓存一分钟
+func GetTimelineKey(ctx context.Context, userID int64, popularAuthorIDs []int64) (key string, err error) {
+	timelineKey := GetKeyUserTimelineZSet(userID)
+	if len(popularAuthorIDs) == 0 {
+		return timelineKey, nil
+	}
+	key = GetKeyUserTimelineMergedZSet(userID)
+	if rdb.Exists(ctx, key).Val() > 0 {
+		return key, nil
+	}
+	keys := make([]string, 0, len(popularAuthorIDs)+1)
+	keys = append(keys, timelineKey)
+	for _, authorID := range popularAuthorIDs {
+		keys = append(keys, GetKeyUserPostsZSet(authorID))
+	}
+	pipe := rdb.Pipeline()
+	pipe.ZUnionStore(ctx, key, &redis.ZStore{
+		Keys:      keys,
+		Aggregate: "MAX",
+	})
+	pipe.Expire(ctx, key, TimelineMergedExpireTime)
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.ZUnionStore timeline failed", zap.Int64("user_id", userID), zap.Error(err))
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"web_app/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// SetUserKarma 用数据库中作者在各社区的karma覆盖其在社区和全站karma排行中的分数,重复写入结果相同
+func SetUserKarma(ctx context.Context, userID int64, karmas []*models.UserKarma) (err error) {
+	member := strconv.FormatInt(userID, 10)
+	var total int64
+	pipe := rdb.TxPipeline()
+	for _, karma := range karmas {
+		total += karma.Karma
+		pipe.ZAdd(ctx, GetKeyCommunityKarmaZSet(karma.CommunityID), &redis.Z{
+			Score:  float64(karma.Karma),
+			Member: member,
+		})
+	}
+	pipe.ZAdd(ctx, GetKeyKarmaZSet(), &redis.Z{
+		Score:  float64(total),
+		Member: member,
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetUserKarma 获得作者的karma,不在排行中时返回0
+func GetUserKarma(ctx context.Context, userID int64) (karma int64, err error) {
+	score, err := rdb.ZScore(ctx, GetKeyKarmaZSet(), strconv.FormatInt(userID, 10)).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return int64(score), err
+}
+
+// GetKarmaLeaderboard 获得karma最高的size位作者,communityID为0时获得全站排行
+func GetKarmaLeaderboard(ctx context.Context, communityID, size int64) (karmas []*models.UserKarma, err error) {
+	key := GetKeyKarmaZSet()
+	if communityID != 0 {
+		key = GetKeyCommunityKarmaZSet(communityID)
+	}
+	results, err := rdb.ZRevRangeWithScores(ctx, key, 0, size-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	karmas = make([]*models.UserKarma, 0, len(results))
+	for _, z := range results {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		userID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		karmas = append(karmas, &models.UserKarma{
+			UserID:      userID,
+			CommunityID: communityID,
+			Karma:       int64(z.Score),
+		})
+	}
+	return karmas, nil
+}
+
+// SetUserKarmas 用重新统计的结果替换全站和社区的karma排行
+func SetUserKarmas(ctx context.Context, karmas []*models.UserKarma) (err error) {
+	// 找到已有的社区karma排行,重新统计后没有karma的社区排行也要删除
+	keys := []string{GetKeyKarmaZSet()}
+	iter := rdb.Scan(ctx, 0, Prefix+"karma:community:*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err = iter.Err(); err != nil {
+		return err
+	}
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, keys...)
+	totals := make(map[int64]int64, len(karmas))
+	for _, karma := range karmas {
+		totals[karma.UserID] += karma.Karma
+		pipe.ZAdd(ctx, GetKeyCommunityKarmaZSet(karma.CommunityID), &redis.Z{
+			Score:  float64(karma.Karma),
+			Member: karma.UserID,
+		})
+	}
+	for userID, total := range totals {
+		pipe.ZAdd(ctx, GetKeyKarmaZSet(), &redis.Z{
+			Score:  float64(total),
+			Member: userID,
+		})
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
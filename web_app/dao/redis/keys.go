@@ -10,10 +10,22 @@ const (
 	KeyVotePostPF  = Prefix + "vote:post:" //帖子投票模块前缀
 )
 
-// KeyUserRefreshToken 获取用户RefreshToken的Key,键值对存储方式
-// lightning:user:<user_id>
-func GetKeyUserRefreshToken(userID int64) string {
-	return fmt.Sprintf("%s%d:refresh_token", KeyUserPF, userID)
+// GetKeyUserSessionHash 获取用户登录会话的Key,Hash存储方式,保存会话的RefreshToken和登录设备
+// lightning:user:<user_id>:session:<session_id>
+func GetKeyUserSessionHash(userID int64, sessionID string) string {
+	return fmt.Sprintf("%s%d:session:%s", KeyUserPF, userID, sessionID)
+}
+
+// GetKeyUserSessionsZSet 获取用户所有登录会话的Key,ZSet存储方式,分数为会话过期时间
+// lightning:user:<user_id>:sessions
+func GetKeyUserSessionsZSet(userID int64) string {
+	return fmt.Sprintf("%s%d:sessions", KeyUserPF, userID)
+}
+
+// GetKeySessionRevoked 获取已撤销会话的Key,键值对存储方式,在AccessToken过期前拒绝该会话的AccessToken
+// lightning:session:revoked:<session_id>
+func GetKeySessionRevoked(sessionID string) string {
+	return Prefix + "session:revoked:" + sessionID
 }
 
 // GetKeyCommunityListHash 获取社区详细信息的Key,Hash存储方式
@@ -69,3 +81,108 @@ func GetKeyCommunityPostScoreZSet(communityID int64) string {
 func GetKeyCommunityPostTimeZSet(communityID int64) string {
 	return fmt.Sprintf("%s%d:post:time", KeyCommunityPF, communityID)
 }
+
+// GetKeyPostCommentNum 获取帖子评论数量的Key,String存储方式
+// lightning:post:<post_id>:comment_num
+func GetKeyPostCommentNum(postID int64) string {
+	return fmt.Sprintf("%s%d:comment_num", KeyPostPF, postID)
+}
+
+// GetKeyVotePostNumHash 获取帖子赞成票和反对票数量的Key,Hash存储方式,字段为up和down
+// lightning:vote:post:<post_id>:num
+func GetKeyVotePostNumHash(postID int64) string {
+	return fmt.Sprintf("%s%d:num", KeyVotePostPF, postID)
+}
+
+// GetKeyVoteArchiveCursor 获取投票归档进度的Key,String存储方式,值为已归档帖子的最晚创建时间
+// lightning:vote:archive:cursor
+func GetKeyVoteArchiveCursor() string {
+	return Prefix + "vote:archive:cursor"
+}
+
+// GetKeyPostRankZSet 获取按排序策略排序的帖子的Key,ZSet存储方式,score策略使用 lightning:post:score
+// lightning:post:rank:<name>
+func GetKeyPostRankZSet(name string) string {
+	if name == "score" {
+		return GetKeyPostScoreZSet()
+	}
+	return KeyPostPF + "rank:" + name
+}
+
+// GetKeyCommunityPostRankZSet 获取按排序策略排序的社区帖子的Key,ZSet存储方式
+// lightning:community:<community_id>:post:<name>
+func GetKeyCommunityPostRankZSet(communityID int64, name string) string {
+	return fmt.Sprintf("%s%d:post:%s", KeyCommunityPF, communityID, name)
+}
+
+// GetKeyCommunityArchivedSet 获取已归档社区ID的Key,Set存储方式
+// lightning:community:archived
+func GetKeyCommunityArchivedSet() string {
+	return KeyCommunityPF + "archived"
+}
+
+// GetKeyPostActiveZSet 获取排除已归档社区帖子后按排序方式排序的全站帖子的Key,ZSet存储方式
+// lightning:post:active:<order>
+func GetKeyPostActiveZSet(order string) string {
+	return KeyPostPF + "active:" + order
+}
+
+// GetKeyUserFeedZSet 获取用户加入的社区的帖子按排序方式排序的Key,ZSet存储方式
+// lightning:user:<user_id>:feed:<order>
+func GetKeyUserFeedZSet(userID int64, order string) string {
+	return fmt.Sprintf("%s%d:feed:%s", KeyUserPF, userID, order)
+}
+
+// GetKeyUserPostsZSet 获取用户发布的帖子按时间排序的Key,ZSet存储方式
+// lightning:user:<user_id>:posts
+func GetKeyUserPostsZSet(userID int64) string {
+	return fmt.Sprintf("%s%d:posts", KeyUserPF, userID)
+}
+
+// GetKeyUserPostsLoaded 获取作者的帖子已从数据库加载的标记Key,键值对存储方式,没有帖子的作者也有该标记
+// lightning:user:<user_id>:posts:loaded
+func GetKeyUserPostsLoaded(userID int64) string {
+	return fmt.Sprintf("%s%d:posts:loaded", KeyUserPF, userID)
+}
+
+// GetKeyUserTimelineZSet 获取推送到用户时间线的帖子按时间排序的Key,ZSet存储方式
+// lightning:user:<user_id>:timeline
+func GetKeyUserTimelineZSet(userID int64) string {
+	return fmt.Sprintf("%s%d:timeline", KeyUserPF, userID)
+}
+
+// GetKeyUserTimelineMergedZSet 获取用户时间线与高粉丝作者帖子合并结果的Key,ZSet存储方式
+// lightning:user:<user_id>:timeline:merged
+func GetKeyUserTimelineMergedZSet(userID int64) string {
+	return fmt.Sprintf("%s%d:timeline:merged", KeyUserPF, userID)
+}
+
+// GetKeyKarmaZSet 获取作者karma排行的Key,ZSet存储方式
+// lightning:karma:user
+func GetKeyKarmaZSet() string {
+	return Prefix + "karma:user"
+}
+
+// GetKeyCommunityKarmaZSet 获取作者在社区中karma排行的Key,ZSet存储方式
+// lightning:karma:community:<community_id>
+func GetKeyCommunityKarmaZSet(communityID int64) string {
+	return fmt.Sprintf("%skarma:community:%d", Prefix, communityID)
+}
+
+// GetKeyPasswordReset 获取重置密码token的Key,键值对存储方式,值为用户ID
+// lightning:password_reset:<token_hash>
+func GetKeyPasswordReset(tokenHash string) string {
+	return Prefix + "password_reset:" + tokenHash
+}
+
+// GetKeyUserPasswordReset 获取用户当前有效的重置密码token的Key,键值对存储方式,值为token的哈希值
+// lightning:password_reset:user:<user_id>
+func GetKeyUserPasswordReset(userID int64) string {
+	return fmt.Sprintf("%spassword_reset:user:%d", Prefix, userID)
+}
+
+// GetKeyEmailVerify 获取邮箱验证token的Key,Hash存储方式,保存用户ID和待验证的邮箱
+// lightning:email_verify:<token_hash>
+func GetKeyEmailVerify(tokenHash string) string {
+	return Prefix + "email_verify:" + tokenHash
+}
@@ -11,12 +11,47 @@ import (
 	"go.uber.org/zap"
 )
 
+// rankNames 启用的排序策略名称,删除和移动帖子时一并维护各策略的ZSet
+var rankNames []string
+
+// SetRankNames 设置启用的排序策略名称
+func SetRankNames(names []string) {
+	rankNames = names
+}
+
 const (
 	NewPostExpireTime           = 24 * time.Hour   // 新帖子过期时间设为一天
 	OldPostExpireTime           = 24 * time.Hour   // 旧帖子过期时间设为一天
 	CommunityPostListExpireTime = 60 * time.Second // 有序社区帖子列表过期时间设为一分钟
 )
 
+// pageScript 获得ZSet中排在游标(分数,成员)之后的帖子,同分帖子按成员排序,二分查找游标位置避免遍历所有同分帖子
+// KEYS[1] 帖子排序ZSet
+// ARGV[1] 游标分数  ARGV[2] 游标成员  ARGV[3] 数量  ARGV[4] 1表示按分数升序取游标之前的帖子,0表示按分数降序取游标之后的帖子
+// 返回成员和分数交替排列的数组
+var pageScript = redis.NewScript(`
+local before = ARGV[4] == "1"
+local range, lo
+if before then
+	range = "ZRANGE"
+	lo = redis.call("ZCOUNT", KEYS[1], "-inf", "(" .. ARGV[1])
+else
+	range = "ZREVRANGE"
+	lo = redis.call("ZCOUNT", KEYS[1], "(" .. ARGV[1], "+inf")
+end
+local hi = lo + redis.call("ZCOUNT", KEYS[1], ARGV[1], ARGV[1])
+while lo < hi do
+	local mid = math.floor((lo + hi) / 2)
+	local member = redis.call(range, KEYS[1], mid, mid)[1]
+	if (before and member > ARGV[2]) or (not before and member < ARGV[2]) then
+		hi = mid
+	else
+		lo = mid + 1
+	end
+end
+return redis.call(range, KEYS[1], lo, lo + tonumber(ARGV[3]) - 1, "WITHSCORES")
+`)
+
 // CreatePost 创建帖子
 func CreatePost(ctx context.Context, post *models.Post) (err error) {
 	postMap := map[string]interface{}{
@@ -25,6 +60,7 @@ func CreatePost(ctx context.Context, post *models.Post) (err error) {
 		"content":      post.Content,
 		"author_id":    post.AuthorID,
 		"community_id": post.CommunityID,
+		"status":       post.Status,
 		"create_time":  post.CreatTime,
 	}
 	// 将帖子信息存入 lightning:post:<post_id> Hash
@@ -48,10 +84,31 @@ func CreatePost(ctx context.Context, post *models.Post) (err error) {
 	// 将帖子存入其社区 lightning:community:<community_id>:posts Set
 	key = GetKeyCommunityPostsSet(post.CommunityID)
 	txPipe.SAdd(ctx, key, post.PostID)
+	// 存入 lightning:user:<author_id>:posts ZSet,作者的帖子未加载时由加载标记决定是否从数据库补齐
+	txPipe.ZAdd(ctx, GetKeyUserPostsZSet(post.AuthorID), &redis.Z{
+		Score:  float64(createTimeUnix),
+		Member: post.PostID,
+	})
 	_, err = txPipe.Exec(ctx)
 	return err
 }
 
+// restoreScoreScript 按帖子的投票数和评论数重新计算帖子在 lightning:post:score 中的分数
+// KEYS[1] lightning:post:score  KEYS[2] lightning:vote:post:<post_id>:num  KEYS[3] lightning:post:<post_id>:comment_num
+// ARGV[1] post_id  ARGV[2] 发帖时间  ARGV[3] 每票分数  ARGV[4] 每条评论分数  ARGV[5] 评论数量缓存不存在时使用的评论数量
+var restoreScoreScript = redis.NewScript(`
+local nums = redis.call("HMGET", KEYS[2], "up", "down")
+local comments = tonumber(redis.call("GET", KEYS[3])) or tonumber(ARGV[5])
+local score = tonumber(ARGV[2]) + ((tonumber(nums[1]) or 0) - (tonumber(nums[2]) or 0)) * tonumber(ARGV[3]) + comments * tonumber(ARGV[4])
+return redis.call("ZADD", KEYS[1], "XX", score, ARGV[1])
+`)
+
+// RestorePostScore 被删除的帖子恢复后按投票数和评论数重新计算分数,与投票和评论的增量更新互斥
+func RestorePostScore(ctx context.Context, postID, createTime, commentNum int64, scorePerVote, scorePerComment int) (err error) {
+	keys := []string{GetKeyPostScoreZSet(), GetKeyVotePostNumHash(postID), GetKeyPostCommentNum(postID)}
+	return restoreScoreScript.Run(ctx, rdb, keys, postID, createTime, scorePerVote, scorePerComment, commentNum).Err()
+}
+
 // InsertPost 旧帖子设置到缓存中
 func InsertPost(ctx context.Context, post *models.Post) (err error) {
 	postMap := map[string]interface{}{
@@ -60,6 +117,7 @@ func InsertPost(ctx context.Context, post *models.Post) (err error) {
 		"content":      post.Content,
 		"author_id":    post.AuthorID,
 		"community_id": post.CommunityID,
+		"status":       post.Status,
 		"create_time":  post.CreatTime,
 	}
 	// 将帖子信息存入 lightning:post:<post_id> Hash
@@ -71,6 +129,46 @@ func InsertPost(ctx context.Context, post *models.Post) (err error) {
 	return err
 }
 
+// DeletePost 删除帖子缓存,并将帖子从排序ZSet和社区Set中移除
+func DeletePost(ctx context.Context, post *models.Post) (err error) {
+	member := strconv.FormatInt(post.PostID, 10)
+	txPipe := rdb.TxPipeline()
+	// 删除 lightning:post:<post_id> Hash
+	txPipe.Del(ctx, GetKeyPostHash(post.PostID))
+	// 从 lightning:post:time 和 lightning:post:score ZSet 中移除
+	txPipe.ZRem(ctx, GetKeyPostTimeZSet(), member)
+	txPipe.ZRem(ctx, GetKeyPostScoreZSet(), member)
+	// 从 lightning:community:<community_id>:posts Set 中移除
+	txPipe.SRem(ctx, GetKeyCommunityPostsSet(post.CommunityID), member)
+	// 从 lightning:user:<author_id>:posts ZSet 中移除
+	txPipe.ZRem(ctx, GetKeyUserPostsZSet(post.AuthorID), member)
+	// 合并后的社区帖子列表在过期前仍会返回该帖子,一并移除
+	txPipe.ZRem(ctx, GetKeyCommunityPostTimeZSet(post.CommunityID), member)
+	txPipe.ZRem(ctx, GetKeyCommunityPostScoreZSet(post.CommunityID), member)
+	// 从各排序策略的 ZSet 中移除
+	for _, name := range rankNames {
+		txPipe.ZRem(ctx, GetKeyPostRankZSet(name), member)
+		txPipe.ZRem(ctx, GetKeyCommunityPostRankZSet(post.CommunityID, name), member)
+	}
+	_, err = txPipe.Exec(ctx)
+	return err
+}
+
+// MovePost 将帖子从旧社区移动到新社区
+func MovePost(ctx context.Context, post *models.Post, oldCommunityID int64) (err error) {
+	member := strconv.FormatInt(post.PostID, 10)
+	txPipe := rdb.TxPipeline()
+	txPipe.SRem(ctx, GetKeyCommunityPostsSet(oldCommunityID), member)
+	txPipe.ZRem(ctx, GetKeyCommunityPostTimeZSet(oldCommunityID), member)
+	txPipe.ZRem(ctx, GetKeyCommunityPostScoreZSet(oldCommunityID), member)
+	for _, name := range rankNames {
+		txPipe.ZRem(ctx, GetKeyCommunityPostRankZSet(oldCommunityID, name), member)
+	}
+	txPipe.SAdd(ctx, GetKeyCommunityPostsSet(post.CommunityID), member)
+	_, err = txPipe.Exec(ctx)
+	return err
+}
+
 // GetPost 获取帖子信息
 func GetPost(ctx context.Context, key string) (post *models.Post, err error) {
 	data, err := rdb.HGetAll(ctx, key).Result()
@@ -81,6 +179,64 @@ func GetPost(ctx context.Context, key string) (post *models.Post, err error) {
 		zap.L().Error("Get post failed", zap.Error(err))
 		return nil, err
 	}
+	return parsePost(data)
+}
+
+// GetPosts 使用管道批量获取帖子信息,缓存中没有的帖子不在结果中
+func GetPosts(ctx context.Context, postIDs []int64) (posts map[int64]*models.Post, err error) {
+	posts = make(map[int64]*models.Post, len(postIDs))
+	if len(postIDs) == 0 {
+		return posts, nil
+	}
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, 0, len(postIDs))
+	for _, postID := range postIDs {
+		cmds = append(cmds, pipe.HGetAll(ctx, GetKeyPostHash(postID)))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.Exec HGetAll posts failed", zap.Error(err))
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 { //缓存未命中
+			continue
+		}
+		post, err := parsePost(data)
+		if err != nil {
+			zap.L().Error("parsePost failed", zap.Int64("post_id", postIDs[i]), zap.Error(err))
+			continue
+		}
+		posts[postIDs[i]] = post
+	}
+	return posts, nil
+}
+
+// InsertPosts 使用管道将多个旧帖子设置到缓存中
+func InsertPosts(ctx context.Context, posts []*models.Post) (err error) {
+	if len(posts) == 0 {
+		return nil
+	}
+	pipe := rdb.Pipeline()
+	for _, post := range posts {
+		key := GetKeyPostHash(post.PostID)
+		pipe.HSet(ctx, key, map[string]interface{}{
+			"post_id":      post.PostID,
+			"title":        post.Title,
+			"content":      post.Content,
+			"author_id":    post.AuthorID,
+			"community_id": post.CommunityID,
+			"status":       post.Status,
+			"create_time":  post.CreatTime,
+		})
+		pipe.Expire(ctx, key, OldPostExpireTime)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// parsePost 将帖子Hash解析为帖子结构体
+func parsePost(data map[string]string) (post *models.Post, err error) {
 	postIDStr := data["post_id"]
 	postID, err := strconv.ParseInt(postIDStr, 10, 64)
 	if err != nil {
@@ -105,10 +261,21 @@ func GetPost(ctx context.Context, key string) (post *models.Post, err error) {
 		zap.L().Error("createTimeStr time.Parse failed", zap.Error(err))
 		return nil, err
 	}
+	// 旧缓存中可能没有status字段,默认为正常帖子
+	status := models.PostStatusNormal
+	if statusStr, ok := data["status"]; ok {
+		parsedStatus, err := strconv.ParseInt(statusStr, 10, 32)
+		if err != nil {
+			zap.L().Error("statusStr strconv.ParseInt failed", zap.Error(err))
+			return nil, err
+		}
+		status = int32(parsedStatus)
+	}
 	post = &models.Post{
 		PostID:      postID,
 		AuthorID:    authorID,
 		CommunityID: communityID,
+		Status:      status,
 		Title:       data["title"],
 		Content:     data["content"],
 		CreatTime:   createTime,
@@ -116,51 +283,74 @@ func GetPost(ctx context.Context, key string) (post *models.Post, err error) {
 	return post, nil
 }
 
-// GetVoteNum 通过帖子id获取投票数据
-func GetVoteNum(ctx context.Context, postID int64) (voteNum int64, err error) {
-	key := GetKeyVotePostHash(postID)
-	results, err := rdb.HGetAll(ctx, key).Result()
-	if err == redis.Nil {
-		zap.L().Warn("vote data not found ",
-			zap.Int64("post_id", postID),
-		)
-		return 0, nil
+// GetPostList 获得key对应的ZSet中排在游标(cursorScore,cursorID)之后的最多size篇帖子,before为true时获得排在游标之前的帖子
+// 结果按分数从高到低返回帖子ID和分数,cursorID为空时从头开始,游标帖子的分数变化或被删除不影响分页
+func GetPostList(ctx context.Context, key string, cursorID string, cursorScore float64, size int64, before bool) (postIDStrs []string, scores []float64, err error) {
+	// 没有游标时从分数最高的帖子开始
+	if cursorID == "" {
+		results, err := rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Max:   "+inf",
+			Min:   "-inf",
+			Count: size,
+		}).Result()
+		if err != nil {
+			zap.L().Error("rdb.ZRevRangeByScoreWithScores failed", zap.Error(err))
+			return nil, nil, err
+		}
+		postIDStrs, scores = splitZ(results)
+		return postIDStrs, scores, nil
 	}
+	// 同分数的帖子按成员倒序排列,在同分帖子中二分查找游标的位置,再按排名取出之后(before时为之前)的帖子
+	scoreStr := strconv.FormatFloat(cursorScore, 'f', -1, 64)
+	beforeArg := 0
+	if before {
+		beforeArg = 1
+	}
+	values, err := pageScript.Run(ctx, rdb, []string{key}, scoreStr, cursorID, size, beforeArg).Slice()
 	if err != nil {
-		zap.L().Error("rdb.HGetAll failed",
-			zap.Int64("post_id", postID),
+		zap.L().Error("pageScript.Run failed",
+			zap.String("cursor_id", cursorID),
+			zap.Float64("cursor_score", cursorScore),
+			zap.Bool("before", before),
 			zap.Error(err),
 		)
-		return 0, err
+		return nil, nil, err
 	}
-	voteNum = 0
-	for _, valueStr := range results {
-		value, err := strconv.ParseInt(valueStr, 10, 64)
-		if err != nil {
-			zap.L().Error("strconv.ParseInt failed")
-			continue
+	results, err := parseZWithScores(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 向前查询得到的是从近到远的升序结果,翻转为按分数从高到低
+	if before {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
 		}
-		voteNum += value
 	}
-	return voteNum, nil
+	postIDStrs, scores = splitZ(results)
+	return postIDStrs, scores, nil
 }
 
-// GetPostList 获得帖子列表，返回帖子ID的字符串切片
-func GetPostList(ctx context.Context, p *models.ParamGetPostsInOrder, cursor string, pageSize int64) (postIDStrs []string, err error) {
+// GetPostListKey 获得帖子列表使用的ZSet,没有指定社区时使用全站帖子的ZSet
+// 社区帖子列表和排除已归档社区的全站帖子列表按需合并并缓存一分钟
+func GetPostListKey(ctx context.Context, p *models.ParamGetPostsInOrder) (key string, err error) {
+	orderKey := getOrderKey(p.Order)
+	if p.CommunityID == 0 {
+		if !p.ExcludeArchived {
+			return orderKey, nil
+		}
+		return getActivePostListKey(ctx, p.Order, orderKey)
+	}
 	// 将保存社区内的所有帖子的Set和保存所有帖子的ZSet合并
 	ckey := GetKeyCommunityPostsSet(p.CommunityID)
-	orderKey := GetKeyPostScoreZSet() //默认帖子按照分数排序
-	key := GetKeyCommunityPostScoreZSet(p.CommunityID)
-	if p.Order == "time" {
-		orderKey = GetKeyPostTimeZSet()
-		key = GetKeyCommunityPostTimeZSet(p.CommunityID)
-	}
+	key = GetKeyCommunityPostRankZSet(p.CommunityID, p.Order)
 	// 如果合并后的 ZSet 不存在，创建
 	if rdb.Exists(ctx, key).Val() < 1 {
 		pipe := rdb.Pipeline()
+		// Set中成员的分数为1,权重设为0使合并结果只保留排序ZSet的分数
 		pipe.ZInterStore(ctx, key, &redis.ZStore{
 			Keys:      []string{ckey, orderKey},
-			Aggregate: "MAX",
+			Weights:   []float64{0, 1},
+			Aggregate: "SUM",
 		})
 		pipe.Expire(ctx, key, CommunityPostListExpireTime)
 		_, err = pipe.Exec(ctx)
@@ -169,30 +359,225 @@ func GetPostList(ctx context.Context, p *models.ParamGetPostsInOrder, cursor str
 				zap.Int64("community_id", p.CommunityID),
 				zap.Error(err),
 			)
-			return nil, err
+			return "", err
 		}
 	}
-	// 设置起始索引
-	var start int64 = 0
-	if cursor != "" {
-		start, err = rdb.ZRevRank(ctx, key, cursor).Result() // 查询游标的索引
-		if err != nil {
-			zap.L().Warn("rdb.ZRevRank failed",
-				zap.String("cursor", cursor),
-				zap.Error(err),
-			)
-			start = 0
+	return key, nil
+}
+
+// GetFeedKey 获得用户加入的社区的帖子列表使用的ZSet,合并结果缓存一分钟
+// 先将各社区的帖子Set合并,再与排序ZSet求交集,只保留排序ZSet的分数
+func GetFeedKey(ctx context.Context, userID int64, order string, communityIDs []int64) (key string, err error) {
+	key = GetKeyUserFeedZSet(userID, order)
+	if rdb.Exists(ctx, key).Val() > 0 {
+		return key, nil
+	}
+	pipe := rdb.TxPipeline()
+	if len(communityIDs) == 0 {
+		// 没有加入社区时信息流为空,删除旧的合并结果
+		pipe.Del(ctx, key)
+	} else {
+		keys := make([]string, 0, len(communityIDs))
+		for _, communityID := range communityIDs {
+			keys = append(keys, GetKeyCommunityPostsSet(communityID))
 		}
+		pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: keys})
+		pipe.ZInterStore(ctx, key, &redis.ZStore{
+			Keys:      []string{key, getOrderKey(order)},
+			Weights:   []float64{0, 1},
+			Aggregate: "SUM",
+		})
+		pipe.Expire(ctx, key, CommunityPostListExpireTime)
 	}
-	// 根据索引查询帖子
-	postIDStrs, err = rdb.ZRevRange(ctx, key, start, start+pageSize-1).Result()
-	if err != nil {
-		zap.L().Error("rdb.ZRevRange failed",
-			zap.Int64("start", start),
-			zap.Int64("stop", start+pageSize-1),
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.ZInterStore feed failed",
+			zap.Int64("user_id", userID),
+			zap.String("order", order),
 			zap.Error(err),
 		)
+		return "", err
+	}
+	return key, nil
+}
+
+// DeleteFeed 用户加入或退出社区后删除其合并的帖子列表
+func DeleteFeed(ctx context.Context, userID int64) (err error) {
+	keys := make([]string, 0, len(rankNames)+1)
+	keys = append(keys, GetKeyUserFeedZSet(userID, models.OrderTime))
+	for _, name := range rankNames {
+		keys = append(keys, GetKeyUserFeedZSet(userID, name))
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
+
+// getOrderKey 获得排序方式对应的全站帖子ZSet
+func getOrderKey(order string) string {
+	if order == models.OrderTime {
+		return GetKeyPostTimeZSet()
+	}
+	return GetKeyPostRankZSet(order)
+}
+
+// getActivePostListKey 从全站帖子的ZSet中去掉已归档社区的帖子,没有已归档社区时直接使用全站帖子的ZSet
+func getActivePostListKey(ctx context.Context, order, orderKey string) (key string, err error) {
+	key = GetKeyPostActiveZSet(order)
+	if rdb.Exists(ctx, key).Val() > 0 {
+		return key, nil
+	}
+	archivedIDs, err := rdb.SMembers(ctx, GetKeyCommunityArchivedSet()).Result()
+	if err != nil {
+		zap.L().Error("rdb.SMembers archived communities failed", zap.Error(err))
+		return "", err
+	}
+	if len(archivedIDs) == 0 {
+		return orderKey, nil
+	}
+	keys := make([]string, 0, len(archivedIDs)+1)
+	keys = append(keys, orderKey)
+	for _, idStr := range archivedIDs {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, GetKeyCommunityPostsSet(id))
+	}
+	pipe := rdb.Pipeline()
+	pipe.ZDiffStore(ctx, key, keys...)
+	pipe.Expire(ctx, key, CommunityPostListExpireTime)
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.ZDiffStore failed", zap.String("order", order), zap.Error(err))
+		return "", err
+	}
+	return key, nil
+}
+
+// splitZ 将ZSet查询结果拆分为成员和分数
+func splitZ(results []redis.Z) (members []string, scores []float64) {
+	members = make([]string, 0, len(results))
+	scores = make([]float64, 0, len(results))
+	for _, z := range results {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		members = append(members, member)
+		scores = append(scores, z.Score)
+	}
+	return members, scores
+}
+
+// parseZWithScores 解析成员和分数交替排列的脚本返回值
+func parseZWithScores(values []interface{}) (results []redis.Z, err error) {
+	if len(values)%2 != 0 {
+		return nil, ErrorInvalidDataFormat
+	}
+	results = make([]redis.Z, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		member, ok := values[i].(string)
+		if !ok {
+			return nil, ErrorInvalidDataFormat
+		}
+		scoreStr, ok := values[i+1].(string)
+		if !ok {
+			return nil, ErrorInvalidDataFormat
+		}
+		score, err := strconv.ParseFloat(scoreStr, 64)
+		if err != nil {
+			return nil, ErrorParseDataFailed
+		}
+		results = append(results, redis.Z{Score: score, Member: member})
+	}
+	return results, nil
+}
+
+// GetPostCreateTime 获得帖子的创建时间戳,帖子不在时间ZSet中时返回 ErrorDataNotFound
+func GetPostCreateTime(ctx context.Context, postID int64) (createTime int64, err error) {
+	score, err := rdb.ZScore(ctx, GetKeyPostTimeZSet(), strconv.FormatInt(postID, 10)).Result()
+	if err == redis.Nil {
+		return 0, ErrorDataNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(score), nil
+}
+
+// GetPostCreateTimes 按创建时间升序获得创建时间在(min,max]之间的帖子及其创建时间戳
+func GetPostCreateTimes(ctx context.Context, min, max, offset, count int64) (createTimes map[int64]int64, err error) {
+	results, err := rdb.ZRangeByScoreWithScores(ctx, GetKeyPostTimeZSet(), &redis.ZRangeBy{
+		Min:    "(" + strconv.FormatInt(min, 10),
+		Max:    strconv.FormatInt(max, 10),
+		Offset: offset,
+		Count:  count,
+	}).Result()
+	if err != nil {
 		return nil, err
 	}
-	return postIDStrs, nil
+	createTimes = make(map[int64]int64, len(results))
+	for _, z := range results {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		postID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		createTimes[postID] = int64(z.Score)
+	}
+	return createTimes, nil
+}
+
+// SetPostRanks 使用管道更新帖子在各排序策略下的分数,ranks为 策略名称->帖子id->分数
+// 只更新已在排序ZSet中的帖子,与删除帖子并发时不会把已删除的帖子重新加入排序
+func SetPostRanks(ctx context.Context, ranks map[string]map[int64]float64) (err error) {
+	pipe := rdb.Pipeline()
+	for name, scores := range ranks {
+		if len(scores) == 0 {
+			continue
+		}
+		members := make([]*redis.Z, 0, len(scores))
+		for postID, score := range scores {
+			members = append(members, &redis.Z{
+				Score:  score,
+				Member: strconv.FormatInt(postID, 10),
+			})
+		}
+		pipe.ZAddXX(ctx, GetKeyPostRankZSet(name), members...)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// addRankScript 将仍在 lightning:post:time 中的帖子加入排序策略的ZSet
+// KEYS[1] lightning:post:time  KEYS[2] lightning:post:rank:<name>
+// ARGV 帖子id和分数交替排列
+var addRankScript = redis.NewScript(`
+local n = 0
+for i = 1, #ARGV, 2 do
+	if redis.call("ZSCORE", KEYS[1], ARGV[i]) then
+		redis.call("ZADD", KEYS[2], ARGV[i + 1], ARGV[i])
+		n = n + 1
+	end
+end
+return n
+`)
+
+// AddPostRanks 将新创建、恢复或补齐排名的帖子加入各排序策略,ranks为 策略名称->帖子id->分数
+// 已被删除(不在时间ZSet中)的帖子不会加入
+func AddPostRanks(ctx context.Context, ranks map[string]map[int64]float64) (err error) {
+	for name, scores := range ranks {
+		if len(scores) == 0 {
+			continue
+		}
+		args := make([]interface{}, 0, len(scores)*2)
+		for postID, score := range scores {
+			args = append(args, postID, score)
+		}
+		keys := []string{GetKeyPostTimeZSet(), GetKeyPostRankZSet(name)}
+		if err = addRankScript.Run(ctx, rdb, keys, args...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,248 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"web_app/models"
+)
+
+// TestDeletePost 删除帖子时移除帖子缓存、排序ZSet、社区Set和合并后的社区帖子列表,不影响其他帖子
+func TestDeletePost(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	createTime := time.Unix(1700000000, 0)
+	posts := []*models.Post{
+		{PostID: 1, AuthorID: 10, CommunityID: 100, Status: models.PostStatusNormal, Title: "a", CreatTime: createTime},
+		{PostID: 2, AuthorID: 10, CommunityID: 100, Status: models.PostStatusNormal, Title: "b", CreatTime: createTime},
+	}
+	for _, post := range posts {
+		if err := CreatePost(ctx, post); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mr.ZAdd(GetKeyCommunityPostTimeZSet(100), 1700000000, "1")
+	mr.ZAdd(GetKeyCommunityPostTimeZSet(100), 1700000000, "2")
+	mr.ZAdd(GetKeyCommunityPostScoreZSet(100), 1700000000, "1")
+	mr.ZAdd(GetKeyCommunityPostScoreZSet(100), 1700000000, "2")
+
+	if err := DeletePost(ctx, posts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(GetKeyPostHash(1)) {
+		t.Error("post hash should be deleted")
+	}
+	if !mr.Exists(GetKeyPostHash(2)) {
+		t.Error("other post hash should be kept")
+	}
+	for _, key := range []string{
+		GetKeyPostTimeZSet(),
+		GetKeyPostScoreZSet(),
+		GetKeyCommunityPostTimeZSet(100),
+		GetKeyCommunityPostScoreZSet(100),
+	} {
+		if members, _ := mr.ZMembers(key); !reflect.DeepEqual(members, []string{"2"}) {
+			t.Errorf("%s members = %v, want [2]", key, members)
+		}
+	}
+	if members, _ := mr.Members(GetKeyCommunityPostsSet(100)); !reflect.DeepEqual(members, []string{"2"}) {
+		t.Errorf("community posts = %v, want [2]", members)
+	}
+}
+
+// TestGetPosts 批量写入后批量读取帖子,缓存中没有的帖子不在结果中
+func TestGetPosts(t *testing.T) {
+	setupMiniRedis(t)
+	ctx := context.Background()
+
+	createTime := time.Unix(1700000000, 0)
+	posts := []*models.Post{
+		{PostID: 1, AuthorID: 10, CommunityID: 100, Status: models.PostStatusNormal, Title: "a", Content: "x", CreatTime: createTime},
+		{PostID: 2, AuthorID: 20, CommunityID: 200, Status: models.PostStatusDeleted, Title: "b", Content: "y", CreatTime: createTime},
+	}
+	if err := InsertPosts(ctx, posts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetPosts(ctx, []int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(posts) = %d, want 2", len(got))
+	}
+	for _, want := range posts {
+		post := got[want.PostID]
+		if post == nil || post.AuthorID != want.AuthorID || post.CommunityID != want.CommunityID || post.Status != want.Status ||
+			post.Title != want.Title || post.Content != want.Content || !post.CreatTime.Equal(want.CreatTime) {
+			t.Errorf("post %d = %+v, want %+v", want.PostID, post, want)
+		}
+	}
+}
+
+// TestRestorePostScore 恢复的帖子按投票数和评论数重新计算分数,评论数量缓存不存在时使用传入的评论数量
+func TestRestorePostScore(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.ZAdd(GetKeyPostScoreZSet(), 1000, "1")
+	mr.HSet(GetKeyVotePostNumHash(1), VoteNumFieldUp, "3", VoteNumFieldDown, "1")
+	mr.Set(GetKeyPostCommentNum(1), "2")
+	if err := RestorePostScore(ctx, 1, 1000, 0, 432, 216); err != nil {
+		t.Fatal(err)
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), "1"); score != 1000+2*432+2*216 {
+		t.Errorf("score = %v, want %v", score, 1000+2*432+2*216)
+	}
+
+	mr.ZAdd(GetKeyPostScoreZSet(), 2000, "2")
+	if err := RestorePostScore(ctx, 2, 2000, 3, 432, 216); err != nil {
+		t.Fatal(err)
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), "2"); score != 2000+3*216 {
+		t.Errorf("score = %v, want %v", score, 2000+3*216)
+	}
+
+	// 帖子已不在排序中时不重新加入
+	if err := RestorePostScore(ctx, 3, 3000, 0, 432, 216); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(GetKeyPostScoreZSet()); !reflect.DeepEqual(members, []string{"1", "2"}) {
+		t.Errorf("members = %v, want [1 2]", members)
+	}
+}
+
+// TestSetPostRanks 定期刷新只更新仍在排序中的帖子,加入排序时跳过已删除的帖子
+func TestSetPostRanks(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	key := GetKeyPostRankZSet("hot")
+	mr.ZAdd(GetKeyPostTimeZSet(), 100, "1")
+	mr.ZAdd(key, 1, "1")
+
+	// 帖子2已被删除,刷新时不重新加入
+	if err := SetPostRanks(ctx, map[string]map[int64]float64{"hot": {1: 5, 2: 7}}); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(key); !reflect.DeepEqual(members, []string{"1"}) {
+		t.Errorf("members after refresh = %v, want [1]", members)
+	}
+	if score, _ := mr.ZScore(key, "1"); score != 5 {
+		t.Errorf("score = %v, want 5", score)
+	}
+
+	// 新帖子3已写入时间ZSet,帖子2不在时间ZSet中
+	mr.ZAdd(GetKeyPostTimeZSet(), 300, "3")
+	if err := AddPostRanks(ctx, map[string]map[int64]float64{"hot": {2: 7, 3: 1.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(key); !reflect.DeepEqual(members, []string{"3", "1"}) {
+		t.Errorf("members after add = %v, want [3 1]", members)
+	}
+	if score, _ := mr.ZScore(key, "3"); score != 1.5 {
+		t.Errorf("score = %v, want 1.5", score)
+	}
+}
+
+// TestGetPostListTies 同分帖子按成员倒序分页,向后和向前翻页都不重复不遗漏
+func TestGetPostListTies(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	const key = "lightning:test:rank"
+	mr.ZAdd(key, 5, "19")
+	for i := 10; i < 18; i++ {
+		mr.ZAdd(key, 0, strconv.Itoa(i))
+	}
+	mr.ZAdd(key, -1, "09")
+	want := []string{"19", "17", "16", "15", "14", "13", "12", "11", "10", "09"}
+
+	// 向后翻页
+	var (
+		got         []string
+		cursorID    string
+		cursorScore float64
+	)
+	for {
+		ids, scores, err := GetPostList(ctx, key, cursorID, cursorScore, 3, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		got = append(got, ids...)
+		cursorID, cursorScore = ids[len(ids)-1], scores[len(scores)-1]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("forward pages = %v, want %v", got, want)
+	}
+
+	// 从中间的同分帖子向前翻页
+	ids, _, err := GetPostList(ctx, key, "13", 0, 3, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantBefore := []string{"16", "15", "14"}; !reflect.DeepEqual(ids, wantBefore) {
+		t.Errorf("before page = %v, want %v", ids, wantBefore)
+	}
+
+	// 游标帖子已被删除时从其原来的位置继续
+	mr.ZRem(key, "13")
+	ids, _, err = GetPostList(ctx, key, "13", 0, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantAfter := []string{"12", "11", "10"}; !reflect.DeepEqual(ids, wantAfter) {
+		t.Errorf("page after deleted cursor = %v, want %v", ids, wantAfter)
+	}
+}
+
+// TestGetFeedKey 信息流合并用户加入的社区中的帖子并保留排序分数,没有加入社区时为空
+func TestGetFeedKey(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	for i, member := range []string{"1", "2", "3", "4"} {
+		mr.ZAdd(GetKeyPostTimeZSet(), float64(100+i), member)
+	}
+	mr.SAdd(GetKeyCommunityPostsSet(1), "1", "2")
+	mr.SAdd(GetKeyCommunityPostsSet(2), "4")
+	mr.SAdd(GetKeyCommunityPostsSet(3), "3")
+
+	key, err := GetFeedKey(ctx, 10, models.OrderTime, []int64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, scores, err := GetPostList(ctx, key, "", 0, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"4", "2", "1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("feed = %v, want %v", ids, want)
+	}
+	if want := []float64{103, 101, 100}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("feed scores = %v, want %v", scores, want)
+	}
+
+	// 合并结果在过期前直接复用,退出社区后删除
+	mr.SRem(GetKeyCommunityPostsSet(2), "4")
+	if key, err = GetFeedKey(ctx, 10, models.OrderTime, []int64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.ZMembers(key); len(members) != 3 {
+		t.Errorf("cached feed = %v, want 3 posts", members)
+	}
+	if err := DeleteFeed(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if key, err = GetFeedKey(ctx, 10, models.OrderTime, nil); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(key) {
+		t.Error("feed should be empty without joined communities")
+	}
+}
@@ -2,21 +2,326 @@ package redis
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"time"
+	"web_app/models"
 
 	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
 )
 
-// CreateRereshToken 将新创建的RereshToken存入redis
-func CreateRereshToken(ctx context.Context, userID int64, token string, time_duration time.Duration) (err error) {
-	return rdb.Set(ctx, GetKeyUserRefreshToken(userID), token, time_duration).Err()
+// CreateSession 保存新的登录会话,会话和RefreshToken同时过期
+func CreateSession(ctx context.Context, userID int64, session *models.Session, time_duration time.Duration) (err error) {
+	key := GetKeyUserSessionHash(userID, session.SessionID)
+	sessionsKey := GetKeyUserSessionsZSet(userID)
+	now := time.Now()
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"session_id":       session.SessionID,
+		"refresh_token":    session.RefreshToken,
+		"user_agent":       session.UserAgent,
+		"ip":               session.IP,
+		"create_time":      session.CreateTime,
+		"last_active_time": session.LastActiveTime,
+	})
+	pipe.Expire(ctx, key, time_duration)
+	// 记录会话的过期时间,并移除已过期的会话
+	pipe.ZAdd(ctx, sessionsKey, &redis.Z{
+		Score:  float64(now.Add(time_duration).Unix()),
+		Member: session.SessionID,
+	})
+	pipe.ZRemRangeByScore(ctx, sessionsKey, "-inf", strconv.FormatInt(now.Unix(), 10))
+	pipe.Expire(ctx, sessionsKey, time_duration)
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
-// GetRefreshToken 通过用户id获取refreshToken
-func GetRefreshToken(ctx context.Context, userID int64) (token string, err error) {
-	token, err = rdb.Get(ctx, GetKeyUserRefreshToken(userID)).Result()
+// rotateScript 会话中保存的RefreshToken与旧token一致时替换为新token并延长会话有效期
+// 旧token是上一代token且轮换后未超过宽限期时,返回已签发的当前token,用于多个标签页并发刷新
+// 返回{状态,token},会话不存在状态为-1,token已被轮换状态为0,替换成功状态为1并返回新token,宽限期内状态为2并返回当前token
+var rotateScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], "refresh_token")
+if not current then
+	return {-1, ""}
+end
+if current ~= ARGV[1] then
+	local prev = redis.call("HMGET", KEYS[1], "prev_refresh_token", "rotate_time")
+	local grace = tonumber(ARGV[7])
+	if grace > 0 and prev[1] == ARGV[1] and prev[2] and tonumber(ARGV[3]) - tonumber(prev[2]) <= grace then
+		return {2, current}
+	end
+	return {0, ""}
+end
+redis.call("HSET", KEYS[1], "refresh_token", ARGV[2], "prev_refresh_token", ARGV[1], "rotate_time", ARGV[3], "last_active_time", ARGV[8])
+redis.call("EXPIRE", KEYS[1], ARGV[4])
+redis.call("ZADD", KEYS[2], ARGV[5], ARGV[6])
+redis.call("EXPIRE", KEYS[2], ARGV[4])
+return {1, ARGV[2]}
+`)
+
+// RotateRefreshToken 原子地将会话的RefreshToken从oldToken轮换为newToken,返回客户端应使用的refreshToken
+// oldToken在grace内刚被轮换过时返回已签发的token而不是newToken
+// 会话不存在时返回 ErrorSessionNotFound,oldToken已被轮换且超过宽限期时返回 ErrorRefreshTokenRotated
+func RotateRefreshToken(ctx context.Context, userID int64, sessionID, oldToken, newToken string, time_duration, grace time.Duration) (refreshToken string, err error) {
+	now := time.Now()
+	keys := []string{GetKeyUserSessionHash(userID, sessionID), GetKeyUserSessionsZSet(userID)}
+	res, err := rotateScript.Run(ctx, rdb, keys,
+		oldToken,
+		newToken,
+		now.Unix(),
+		int64(time_duration.Seconds()),
+		now.Add(time_duration).Unix(),
+		sessionID,
+		int64(grace.Seconds()),
+		now.Format(time.RFC3339Nano),
+	).Slice()
+	if err != nil {
+		return "", err
+	}
+	if len(res) != 2 {
+		return "", ErrorInvalidDataFormat
+	}
+	status, _ := res[0].(int64)
+	switch status {
+	case -1:
+		return "", ErrorSessionNotFound
+	case 0:
+		return "", ErrorRefreshTokenRotated
+	}
+	refreshToken, _ = res[1].(string)
+	return refreshToken, nil
+}
+
+// GetSession 获得用户的登录会话,会话不存在或已过期时返回 ErrorSessionNotFound
+func GetSession(ctx context.Context, userID int64, sessionID string) (session *models.Session, err error) {
+	data, err := rdb.HGetAll(ctx, GetKeyUserSessionHash(userID, sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrorSessionNotFound
+	}
+	return parseSession(data)
+}
+
+// GetSessions 获得用户所有未过期的登录会话,按登录时间排序
+func GetSessions(ctx context.Context, userID int64) (sessions []*models.Session, err error) {
+	sessionIDs, err := rdb.ZRangeByScore(ctx, GetKeyUserSessionsZSet(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions = make([]*models.Session, 0, len(sessionIDs))
+	if len(sessionIDs) == 0 {
+		return sessions, nil
+	}
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		cmds = append(cmds, pipe.HGetAll(ctx, GetKeyUserSessionHash(userID, sessionID)))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		zap.L().Error("pipe.Exec HGetAll sessions failed", zap.Error(err))
+		return nil, err
+	}
+	for _, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 { //会话已被撤销
+			continue
+		}
+		session, err := parseSession(data)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreateTime.Before(sessions[j].CreateTime)
+	})
+	return sessions, nil
+}
+
+// RevokeSessions 撤销用户的登录会话,sessionIDs为空时撤销所有会话
+// 删除会话使RefreshToken失效,并在revokeDuration内拒绝这些会话的AccessToken
+func RevokeSessions(ctx context.Context, userID int64, sessionIDs []string, revokeDuration time.Duration) (err error) {
+	sessionsKey := GetKeyUserSessionsZSet(userID)
+	if len(sessionIDs) == 0 {
+		if sessionIDs, err = rdb.ZRange(ctx, sessionsKey, 0, -1).Result(); err != nil {
+			return err
+		}
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	pipe := rdb.TxPipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Del(ctx, GetKeyUserSessionHash(userID, sessionID))
+		pipe.ZRem(ctx, sessionsKey, sessionID)
+		pipe.Set(ctx, GetKeySessionRevoked(sessionID), 1, revokeDuration)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// IsSessionRevoked 判断会话是否已被撤销
+func IsSessionRevoked(ctx context.Context, sessionID string) (revoked bool, err error) {
+	n, err := rdb.Exists(ctx, GetKeySessionRevoked(sessionID)).Result()
+	return n > 0, err
+}
+
+// parseSession 将会话Hash解析为会话结构体
+func parseSession(data map[string]string) (session *models.Session, err error) {
+	createTime, err := time.Parse(time.RFC3339, data["create_time"])
+	if err != nil {
+		zap.L().Error("createTime time.Parse failed", zap.Error(err))
+		return nil, err
+	}
+	lastActiveTime, err := time.Parse(time.RFC3339, data["last_active_time"])
+	if err != nil {
+		zap.L().Error("lastActiveTime time.Parse failed", zap.Error(err))
+		return nil, err
+	}
+	session = &models.Session{
+		SessionID:      data["session_id"],
+		UserAgent:      data["user_agent"],
+		IP:             data["ip"],
+		CreateTime:     createTime,
+		LastActiveTime: lastActiveTime,
+		RefreshToken:   data["refresh_token"],
+	}
+	return session, nil
+}
+
+// UserPostsExist 判断作者的帖子是否已加载到 lightning:user:<user_id>:posts ZSet
+// 没有帖子的作者ZSet不存在,以加载标记为准
+func UserPostsExist(ctx context.Context, userID int64) (exist bool, err error) {
+	n, err := rdb.Exists(ctx, GetKeyUserPostsLoaded(userID)).Result()
+	return n > 0, err
+}
+
+// setUserPostsScript 将仍在 lightning:post:time 中的帖子按发帖时间加入作者的帖子ZSet并设置加载标记
+// 从数据库读取之后被删除的帖子已不在时间ZSet中,不会被重新加入
+// KEYS[1] lightning:post:time  KEYS[2] lightning:user:<user_id>:posts  KEYS[3] lightning:user:<user_id>:posts:loaded
+// ARGV 帖子id
+var setUserPostsScript = redis.NewScript(`
+for i = 1, #ARGV do
+	local createTime = redis.call("ZSCORE", KEYS[1], ARGV[i])
+	if createTime then
+		redis.call("ZADD", KEYS[2], createTime, ARGV[i])
+	end
+end
+redis.call("SET", KEYS[3], 1)
+return 1
+`)
+
+// SetUserPosts 将作者的帖子id和发帖时间存入 lightning:user:<user_id>:posts ZSet并设置加载标记
+// 只添加不覆盖,加载期间由binlog消息写入的新帖子不会丢失,加载期间被删除的帖子不会加入
+func SetUserPosts(ctx context.Context, userID int64, posts []*models.Post) (err error) {
+	keys := []string{GetKeyPostTimeZSet(), GetKeyUserPostsZSet(userID), GetKeyUserPostsLoaded(userID)}
+	args := make([]interface{}, 0, len(posts))
+	for _, post := range posts {
+		args = append(args, post.PostID)
+	}
+	return setUserPostsScript.Run(ctx, rdb, keys, args...).Err()
+}
+
+// GetUserPostNum 获得作者发布的帖子数量
+func GetUserPostNum(ctx context.Context, userID int64) (num int64, err error) {
+	return rdb.ZCard(ctx, GetKeyUserPostsZSet(userID)).Result()
+}
+
+// delIfEqualScript key的值与ARGV[1]相同时删除key,返回是否删除
+var delIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// swapResetScript 原子地删除用户旧的重置密码token并保存新token,并发申请时也只有最后保存的token有效
+// KEYS[1] lightning:password_reset:user:<user_id>  KEYS[2] lightning:password_reset:<token_hash>
+// ARGV[1] 重置密码token的Key前缀  ARGV[2] token_hash  ARGV[3] user_id  ARGV[4] 有效期(毫秒)
+var swapResetScript = redis.NewScript(`
+local old = redis.call("GET", KEYS[1])
+if old then
+	redis.call("DEL", ARGV[1] .. old)
+end
+redis.call("SET", KEYS[2], ARGV[3], "PX", ARGV[4])
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[4])
+return 1
+`)
+
+// CreatePasswordReset 保存重置密码token对应的用户ID,每个用户只有最新的token有效,旧token被删除
+func CreatePasswordReset(ctx context.Context, tokenHash string, userID int64, time_duration time.Duration) (err error) {
+	keys := []string{GetKeyUserPasswordReset(userID), GetKeyPasswordReset(tokenHash)}
+	return swapResetScript.Run(ctx, rdb, keys, GetKeyPasswordReset(""), tokenHash, userID, time_duration.Milliseconds()).Err()
+}
+
+// TakePasswordReset 取出并删除重置密码token对应的用户ID,保证token只能使用一次
+// token不存在、已过期或不是用户最新的token时返回 ErrorDataNotFound
+func TakePasswordReset(ctx context.Context, tokenHash string) (userID int64, err error) {
+	userID, err = rdb.GetDel(ctx, GetKeyPasswordReset(tokenHash)).Int64()
 	if err == redis.Nil {
-		return "", ErrorRefreshTokenNotFound
+		return 0, ErrorDataNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := delIfEqualScript.Run(ctx, rdb, []string{GetKeyUserPasswordReset(userID)}, tokenHash).Int()
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, ErrorDataNotFound
+	}
+	return userID, nil
+}
+
+// deleteResetScript 原子地删除用户当前有效的重置密码token
+// KEYS[1] lightning:password_reset:user:<user_id>  ARGV[1] 重置密码token的Key前缀
+var deleteResetScript = redis.NewScript(`
+local old = redis.call("GET", KEYS[1])
+if old then
+	redis.call("DEL", ARGV[1] .. old, KEYS[1])
+end
+return 1
+`)
+
+// DeletePasswordReset 删除用户当前有效的重置密码token
+func DeletePasswordReset(ctx context.Context, userID int64) (err error) {
+	return deleteResetScript.Run(ctx, rdb, []string{GetKeyUserPasswordReset(userID)}, GetKeyPasswordReset("")).Err()
+}
+
+// CreateEmailVerification 保存邮箱验证token对应的用户ID和邮箱
+func CreateEmailVerification(ctx context.Context, tokenHash string, userID int64, email string, time_duration time.Duration) (err error) {
+	key := GetKeyEmailVerify(tokenHash)
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "email", email)
+	pipe.Expire(ctx, key, time_duration)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// TakeEmailVerification 取出并删除邮箱验证token对应的用户ID和邮箱,保证token只能使用一次
+// token不存在或已过期时返回 ErrorDataNotFound
+func TakeEmailVerification(ctx context.Context, tokenHash string) (userID int64, email string, err error) {
+	key := GetKeyEmailVerify(tokenHash)
+	pipe := rdb.TxPipeline()
+	get := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return 0, "", err
+	}
+	data := get.Val()
+	if len(data) == 0 {
+		return 0, "", ErrorDataNotFound
+	}
+	userID, err = strconv.ParseInt(data["user_id"], 10, 64)
+	if err != nil {
+		return 0, "", ErrorParseDataFailed
 	}
-	return token, err
+	return userID, data["email"], nil
 }
@@ -0,0 +1,200 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"web_app/models"
+)
+
+// TestSetUserPosts 没有帖子的作者也记录已加载,加载前由binlog写入的帖子不会被覆盖,加载期间删除的帖子不会加入
+func TestSetUserPosts(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	if err := SetUserPosts(ctx, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if exist, err := UserPostsExist(ctx, 1); err != nil || !exist {
+		t.Fatalf("UserPostsExist(empty author) = %v, %v, want true, nil", exist, err)
+	}
+
+	// 加载期间新帖子先写入
+	if err := CreatePost(ctx, &models.Post{PostID: 20, AuthorID: 2, CommunityID: 1, CreatTime: time.Unix(2000, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := UserPostsExist(ctx, 2); exist {
+		t.Fatal("author posts should not be marked loaded by a new post")
+	}
+	// 帖子11在从数据库读取之后被删除,已不在时间ZSet中
+	mr.ZAdd(GetKeyPostTimeZSet(), 1000, "10")
+	posts := []*models.Post{
+		{PostID: 10, AuthorID: 2, CreatTime: time.Unix(1000, 0)},
+		{PostID: 11, AuthorID: 2, CreatTime: time.Unix(1100, 0)},
+	}
+	if err := SetUserPosts(ctx, 2, posts); err != nil {
+		t.Fatal(err)
+	}
+	if num, err := GetUserPostNum(ctx, 2); err != nil || num != 2 {
+		t.Fatalf("GetUserPostNum = %d, %v, want 2, nil", num, err)
+	}
+	if members, _ := mr.ZMembers(GetKeyUserPostsZSet(2)); !reflect.DeepEqual(members, []string{"10", "20"}) {
+		t.Errorf("members = %v, want [10 20]", members)
+	}
+}
+
+// TestRevokeSessions 撤销指定会话只影响该会话,sessionIDs为空时撤销所有会话,撤销标记在revokeDuration后过期
+func TestRevokeSessions(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	createTime := time.Unix(1700000000, 0)
+	for i, sessionID := range []string{"s1", "s2", "s3"} {
+		session := &models.Session{
+			SessionID:      sessionID,
+			RefreshToken:   "token-" + sessionID,
+			CreateTime:     createTime.Add(time.Duration(i) * time.Minute),
+			LastActiveTime: createTime,
+		}
+		if err := CreateSession(ctx, 1, session, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sessionIDs := func() (ids []string) {
+		sessions, err := GetSessions(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, session := range sessions {
+			ids = append(ids, session.SessionID)
+		}
+		return ids
+	}
+	if got := sessionIDs(); !reflect.DeepEqual(got, []string{"s1", "s2", "s3"}) {
+		t.Fatalf("sessions = %v, want [s1 s2 s3]", got)
+	}
+
+	if err := RevokeSessions(ctx, 1, []string{"s2"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSession(ctx, 1, "s2"); err != ErrorSessionNotFound {
+		t.Errorf("GetSession(revoked) err = %v, want %v", err, ErrorSessionNotFound)
+	}
+	if got := sessionIDs(); !reflect.DeepEqual(got, []string{"s1", "s3"}) {
+		t.Errorf("sessions after revoke = %v, want [s1 s3]", got)
+	}
+	for sessionID, want := range map[string]bool{"s1": false, "s2": true, "s3": false} {
+		if revoked, err := IsSessionRevoked(ctx, sessionID); err != nil || revoked != want {
+			t.Errorf("IsSessionRevoked(%s) = %v, %v, want %v, nil", sessionID, revoked, err, want)
+		}
+	}
+
+	if err := RevokeSessions(ctx, 1, nil, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got := sessionIDs(); len(got) != 0 {
+		t.Errorf("sessions after revoking all = %v, want none", got)
+	}
+	if revoked, _ := IsSessionRevoked(ctx, "s3"); !revoked {
+		t.Error("s3 should be revoked")
+	}
+
+	// AccessToken过期后不再需要撤销标记
+	mr.FastForward(time.Minute)
+	if revoked, _ := IsSessionRevoked(ctx, "s1"); revoked {
+		t.Error("revoked mark should expire")
+	}
+}
+
+// TestRotateRefreshTokenGrace 宽限期内重复使用上一代token返回已签发的token,超过宽限期返回 ErrorRefreshTokenRotated
+func TestRotateRefreshTokenGrace(t *testing.T) {
+	setupMiniRedis(t)
+	ctx := context.Background()
+
+	session := &models.Session{SessionID: "s1", RefreshToken: "t1", CreateTime: time.Now(), LastActiveTime: time.Now()}
+	if err := CreateSession(ctx, 1, session, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	token, err := RotateRefreshToken(ctx, 1, "s1", "t1", "t2", time.Hour, time.Minute)
+	if err != nil || token != "t2" {
+		t.Fatalf("first rotation = %q, %v, want t2, nil", token, err)
+	}
+	// 另一个标签页同时使用t1刷新
+	token, err = RotateRefreshToken(ctx, 1, "s1", "t1", "t2b", time.Hour, time.Minute)
+	if err != nil || token != "t2" {
+		t.Fatalf("concurrent rotation = %q, %v, want t2, nil", token, err)
+	}
+	// 没有宽限期时视为重复使用
+	if _, err = RotateRefreshToken(ctx, 1, "s1", "t1", "t2c", time.Hour, 0); err != ErrorRefreshTokenRotated {
+		t.Fatalf("reuse without grace err = %v, want %v", err, ErrorRefreshTokenRotated)
+	}
+	if _, err = RotateRefreshToken(ctx, 1, "s2", "t1", "t2", time.Hour, time.Minute); err != ErrorSessionNotFound {
+		t.Fatalf("missing session err = %v, want %v", err, ErrorSessionNotFound)
+	}
+}
+
+// TestPasswordResetSingleToken 每个用户只有最新的重置密码token有效,修改密码后token失效
+func TestPasswordResetSingleToken(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	if err := CreatePasswordReset(ctx, "h1", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreatePasswordReset(ctx, "h2", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TakePasswordReset(ctx, "h1"); err != ErrorDataNotFound {
+		t.Fatalf("old token err = %v, want %v", err, ErrorDataNotFound)
+	}
+	if userID, err := TakePasswordReset(ctx, "h2"); err != nil || userID != 1 {
+		t.Fatalf("latest token = %d, %v, want 1, nil", userID, err)
+	}
+	if _, err := TakePasswordReset(ctx, "h2"); err != ErrorDataNotFound {
+		t.Fatalf("reused token err = %v, want %v", err, ErrorDataNotFound)
+	}
+	if mr.Exists(GetKeyUserPasswordReset(1)) {
+		t.Error("user reset key should be deleted after reset")
+	}
+
+	if err := CreatePasswordReset(ctx, "h3", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeletePasswordReset(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TakePasswordReset(ctx, "h3"); err != ErrorDataNotFound {
+		t.Fatalf("token after password change err = %v, want %v", err, ErrorDataNotFound)
+	}
+}
+
+// TestPasswordResetConcurrent 并发申请重置密码时只有一个token保持有效
+func TestPasswordResetConcurrent(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := CreatePasswordReset(ctx, "c"+strconv.Itoa(i), 1, time.Hour); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	live := 0
+	for i := 0; i < n; i++ {
+		if mr.Exists(GetKeyPasswordReset("c" + strconv.Itoa(i))) {
+			live++
+		}
+	}
+	if live != 1 {
+		t.Errorf("live tokens = %d, want 1", live)
+	}
+}
@@ -6,40 +6,234 @@ import (
 	"web_app/models"
 
 	"github.com/go-redis/redis/v8"
-	"go.uber.org/zap"
 )
 
-// GetVoteType 获得当前帖子当前用户的旧投票类型
-func GetVoteType(ctx context.Context, votePost *models.VotePost) (oVoteType int8, err error) {
-	key := GetKeyVotePostHash(votePost.PostID)
-	oVoteTypeStr, err := rdb.HGet(ctx, key, strconv.FormatInt(votePost.UserID, 10)).Result()
-	if err == redis.Nil { // 用户没有给当前帖子投票
-		oVoteType = 0
-		return oVoteType, nil
+const (
+	VoteNumFieldUp   = "up"   // 赞成票数量字段
+	VoteNumFieldDown = "down" // 反对票数量字段
+)
+
+// voteScript 原子地检查投票期限、读取旧投票类型、写入新投票类型、维护赞成票和反对票数量并更新帖子分数
+// KEYS[1] lightning:vote:post:<post_id>  KEYS[2] lightning:vote:post:<post_id>:num  KEYS[3] lightning:post:score
+// KEYS[4] lightning:post:time
+// ARGV[1] user_id  ARGV[2] vote_type  ARGV[3] 每票分数  ARGV[4] post_id  ARGV[5] 允许投票的最早发帖时间,0表示不限制
+// 返回{状态,旧投票类型},帖子不存在(不在时间ZSet中)状态为-1,超出投票期限状态为0,投票成功状态为1,新旧投票类型相同时不做修改
+var voteScript = redis.NewScript(`
+local createTime = redis.call("ZSCORE", KEYS[4], ARGV[4])
+if not createTime then
+	return {-1, 0}
+end
+local deadline = tonumber(ARGV[5])
+if deadline > 0 and tonumber(createTime) < deadline then
+	return {0, 0}
+end
+local old = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "0")
+local new = tonumber(ARGV[2])
+if old == new then
+	return {1, old}
+end
+redis.call("HSET", KEYS[1], ARGV[1], new)
+if old == 1 then
+	redis.call("HINCRBY", KEYS[2], "up", -1)
+elseif old == -1 then
+	redis.call("HINCRBY", KEYS[2], "down", -1)
+end
+if new == 1 then
+	redis.call("HINCRBY", KEYS[2], "up", 1)
+elseif new == -1 then
+	redis.call("HINCRBY", KEYS[2], "down", 1)
+end
+if redis.call("ZSCORE", KEYS[3], ARGV[4]) then
+	redis.call("ZINCRBY", KEYS[3], (new - old) * tonumber(ARGV[3]), ARGV[4])
+end
+return {1, old}
+`)
+
+// VoteForPost 将投票数据存入redis并按新旧投票类型的差值更新帖子分数,返回用户的旧投票类型
+// 帖子不在时间ZSet中(已删除或不存在)时返回 ErrorPostNotExist,帖子创建时间早于deadline时返回 ErrorVoteTimeExpired
+func VoteForPost(ctx context.Context, scorePerVote int, deadline int64, votePost *models.VotePost) (oVoteType int8, err error) {
+	keys := []string{
+		GetKeyVotePostHash(votePost.PostID),
+		GetKeyVotePostNumHash(votePost.PostID),
+		GetKeyPostScoreZSet(),
+		GetKeyPostTimeZSet(),
 	}
-	if err != nil { //查询出错
-		zap.L().Error("rdb.HGet Get voteType failed", zap.Error(err))
+	res, err := voteScript.Run(ctx, rdb, keys, votePost.UserID, votePost.VoteType, scorePerVote, votePost.PostID, deadline).Int64Slice()
+	if err != nil {
 		return 0, err
 	}
-	// 返回投票数据
-	parsedValue, err := strconv.ParseInt(oVoteTypeStr, 10, 8)
+	if len(res) != 2 {
+		return 0, ErrorInvalidDataFormat
+	}
+	switch res[0] {
+	case -1:
+		return 0, ErrorPostNotExist
+	case 0:
+		return 0, ErrorVoteTimeExpired
+	}
+	return int8(res[1]), nil
+}
+
+// GetVoteNum 通过帖子id获取赞成票和反对票数量
+func GetVoteNum(ctx context.Context, postID int64) (voteNum *models.VoteNum, err error) {
+	values, err := rdb.HMGet(ctx, GetKeyVotePostNumHash(postID), VoteNumFieldUp, VoteNumFieldDown).Result()
 	if err != nil {
-		zap.L().Error("strconv.ParseInt failed", zap.Error(err))
-		return 0, err
+		return nil, err
+	}
+	return parseVoteNum(postID, values), nil
+}
+
+// GetVoteNums 使用管道批量获取帖子的赞成票和反对票数量
+func GetVoteNums(ctx context.Context, postIDs []int64) (voteNums map[int64]*models.VoteNum, err error) {
+	voteNums = make(map[int64]*models.VoteNum, len(postIDs))
+	if len(postIDs) == 0 {
+		return voteNums, nil
+	}
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.SliceCmd, 0, len(postIDs))
+	for _, postID := range postIDs {
+		cmds = append(cmds, pipe.HMGet(ctx, GetKeyVotePostNumHash(postID), VoteNumFieldUp, VoteNumFieldDown))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		voteNums[postIDs[i]] = parseVoteNum(postIDs[i], cmd.Val())
 	}
-	oVoteType = int8(parsedValue)
-	return oVoteType, nil
+	return voteNums, nil
 }
 
-// VoteForPost 将投票数据存入redis
-func VoteForPost(ctx context.Context, changeScore int, votePost *models.VotePost) (err error) {
-	key := GetKeyVotePostHash(votePost.PostID)
-	// 将用户名和投票类型存入redis
-	txPipe := rdb.TxPipeline()
-	txPipe.HSet(ctx, key, votePost.UserID, votePost.VoteType)
-	// 更新帖子分数
-	key = GetKeyPostScoreZSet()
-	txPipe.ZIncrBy(ctx, key, float64(changeScore), strconv.FormatInt(votePost.PostID, 10))
-	_, err = txPipe.Exec(ctx)
+// recountScript 按用户投票Hash重新统计一批帖子的赞成票和反对票数量,与投票脚本互斥,不会丢失并发的投票
+// 超出投票期限且用户投票已归档删除的帖子计数不再变化,不重新统计
+// KEYS[1] lightning:post:time
+// ARGV[1] lightning:vote:post: 前缀  ARGV[2] 起始排名  ARGV[3] 结束排名  ARGV[4] 允许投票的最早发帖时间,0表示不限制
+// 返回本批帖子数量
+var recountScript = redis.NewScript(`
+local posts = redis.call("ZRANGE", KEYS[1], ARGV[2], ARGV[3], "WITHSCORES")
+local deadline = tonumber(ARGV[4])
+for i = 1, #posts, 2 do
+	local voteKey = ARGV[1] .. posts[i]
+	if redis.call("EXISTS", voteKey) == 1 or deadline == 0 or tonumber(posts[i + 1]) >= deadline then
+		local up, down = 0, 0
+		for _, v in ipairs(redis.call("HVALS", voteKey)) do
+			if v == "1" then
+				up = up + 1
+			elseif v == "-1" then
+				down = down + 1
+			end
+		end
+		redis.call("HSET", voteKey .. ":num", "up", up, "down", down)
+	end
+end
+return #posts / 2
+`)
+
+// RecountVoteNums 按redis中的用户投票重新统计所有帖子的赞成票和反对票数量,deadline为允许投票的最早发帖时间
+// 用户投票Hash是投票的权威数据,包括还没有经kafka写入数据库的投票;已删除的帖子不在时间ZSet中,保留计数用于恢复
+func RecountVoteNums(ctx context.Context, deadline int64) (err error) {
+	const batchSize = 100
+	for start := int64(0); ; start += batchSize {
+		n, err := recountScript.Run(ctx, rdb, []string{GetKeyPostTimeZSet()}, KeyVotePostPF, start, start+batchSize-1, deadline).Int64()
+		if err != nil {
+			return err
+		}
+		if n < batchSize {
+			return nil
+		}
+	}
+}
+
+// SetVoteNums 使用管道写入帖子的赞成票和反对票数量,用于恢复已归档帖子的计数
+func SetVoteNums(ctx context.Context, voteNums []*models.VoteNum) (err error) {
+	if len(voteNums) == 0 {
+		return nil
+	}
+	pipe := rdb.Pipeline()
+	for _, voteNum := range voteNums {
+		pipe.HSet(ctx, GetKeyVotePostNumHash(voteNum.PostID),
+			VoteNumFieldUp, voteNum.UpNum,
+			VoteNumFieldDown, voteNum.DownNum,
+		)
+	}
+	_, err = pipe.Exec(ctx)
 	return err
 }
+
+// GetVoteArchiveCursor 获得已归档帖子的最晚创建时间,没有归档记录时返回0
+func GetVoteArchiveCursor(ctx context.Context) (cursor int64, err error) {
+	cursor, err = rdb.Get(ctx, GetKeyVoteArchiveCursor()).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+// SetVoteArchiveCursor 记录已归档帖子的最晚创建时间
+func SetVoteArchiveCursor(ctx context.Context, cursor int64) (err error) {
+	return rdb.Set(ctx, GetKeyVoteArchiveCursor(), cursor, 0).Err()
+}
+
+// GetPostIDsByTime 按创建时间升序获得创建时间在(min,max]之间的帖子id
+func GetPostIDsByTime(ctx context.Context, min, max, offset, count int64) (postIDStrs []string, err error) {
+	return rdb.ZRangeByScore(ctx, GetKeyPostTimeZSet(), &redis.ZRangeBy{
+		Min:    "(" + strconv.FormatInt(min, 10),
+		Max:    strconv.FormatInt(max, 10),
+		Offset: offset,
+		Count:  count,
+	}).Result()
+}
+
+// GetVoteArchive 获得帖子当前的分数、赞成票和反对票数量以及所有用户的投票
+func GetVoteArchive(ctx context.Context, postID int64) (archive *models.PostVoteArchive, votes []*models.VotePost, err error) {
+	pipe := rdb.Pipeline()
+	scoreCmd := pipe.ZScore(ctx, GetKeyPostScoreZSet(), strconv.FormatInt(postID, 10))
+	numCmd := pipe.HMGet(ctx, GetKeyVotePostNumHash(postID), VoteNumFieldUp, VoteNumFieldDown)
+	votesCmd := pipe.HGetAll(ctx, GetKeyVotePostHash(postID))
+	if _, err = pipe.Exec(ctx); err != nil && err != redis.Nil { // 帖子不在分数ZSet中时ZScore返回redis.Nil
+		return nil, nil, err
+	}
+	voteNum := parseVoteNum(postID, numCmd.Val())
+	archive = &models.PostVoteArchive{
+		PostID:  postID,
+		Score:   scoreCmd.Val(),
+		UpNum:   voteNum.UpNum,
+		DownNum: voteNum.DownNum,
+	}
+	votes = make([]*models.VotePost, 0, len(votesCmd.Val()))
+	for userIDStr, voteTypeStr := range votesCmd.Val() {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		voteType, err := strconv.ParseInt(voteTypeStr, 10, 8)
+		if err != nil {
+			continue
+		}
+		votes = append(votes, &models.VotePost{
+			PostID:   postID,
+			UserID:   userID,
+			VoteType: int8(voteType),
+		})
+	}
+	return archive, votes, nil
+}
+
+// DeleteVotePostHash 删除帖子的用户投票Hash,赞成票和反对票数量保留用于展示
+func DeleteVotePostHash(ctx context.Context, postID int64) (err error) {
+	return rdb.Del(ctx, GetKeyVotePostHash(postID)).Err()
+}
+
+// parseVoteNum 解析HMGet返回的赞成票和反对票数量,字段不存在时为0
+func parseVoteNum(postID int64, values []interface{}) (voteNum *models.VoteNum) {
+	voteNum = &models.VoteNum{PostID: postID}
+	if len(values) != 2 {
+		return voteNum
+	}
+	if upStr, ok := values[0].(string); ok {
+		voteNum.UpNum, _ = strconv.ParseInt(upStr, 10, 64)
+	}
+	if downStr, ok := values[1].(string); ok {
+		voteNum.DownNum, _ = strconv.ParseInt(downStr, 10, 64)
+	}
+	return voteNum
+}
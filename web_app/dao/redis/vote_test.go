@@ -0,0 +1,174 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"web_app/models"
+)
+
+// TestVoteForPostConcurrent 同一用户并发对同一帖子投相同的票,分数和票数只能变化一次
+func TestVoteForPostConcurrent(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	const (
+		postID       = int64(1001)
+		userID       = int64(2001)
+		createTime   = 1700000000
+		scorePerVote = 432
+		workers      = 50
+	)
+	member := strconv.FormatInt(postID, 10)
+	if _, err := mr.ZAdd(GetKeyPostTimeZSet(), createTime, member); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mr.ZAdd(GetKeyPostScoreZSet(), createTime, member); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		voteType  int8
+		wantScore float64
+		wantUp    string
+		wantDown  string
+	}{
+		{voteType: 1, wantScore: createTime + scorePerVote, wantUp: "1", wantDown: ""},
+		{voteType: -1, wantScore: createTime - scorePerVote, wantUp: "0", wantDown: "1"},
+	}
+	for _, c := range cases {
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			changed int
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				old, err := VoteForPost(ctx, scorePerVote, 0, &models.VotePost{
+					PostID:   postID,
+					UserID:   userID,
+					VoteType: c.voteType,
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if old != c.voteType {
+					mu.Lock()
+					changed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if changed != 1 {
+			t.Errorf("vote %d: %d votes changed the vote type, want 1", c.voteType, changed)
+		}
+		score, err := mr.ZScore(GetKeyPostScoreZSet(), member)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if score != c.wantScore {
+			t.Errorf("vote %d: score = %v, want %v", c.voteType, score, c.wantScore)
+		}
+		numKey := GetKeyVotePostNumHash(postID)
+		if up := mr.HGet(numKey, VoteNumFieldUp); up != c.wantUp {
+			t.Errorf("vote %d: up = %q, want %q", c.voteType, up, c.wantUp)
+		}
+		if down := mr.HGet(numKey, VoteNumFieldDown); down != c.wantDown {
+			t.Errorf("vote %d: down = %q, want %q", c.voteType, down, c.wantDown)
+		}
+	}
+}
+
+// TestVoteForPostExpired 超出投票期限的帖子投票返回 ErrorVoteTimeExpired 且不修改分数
+func TestVoteForPostExpired(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	member := "1002"
+	mr.ZAdd(GetKeyPostTimeZSet(), 100, member)
+	mr.ZAdd(GetKeyPostScoreZSet(), 100, member)
+
+	_, err := VoteForPost(ctx, 432, 200, &models.VotePost{PostID: 1002, UserID: 1, VoteType: 1})
+	if err != ErrorVoteTimeExpired {
+		t.Fatalf("err = %v, want %v", err, ErrorVoteTimeExpired)
+	}
+	if score, _ := mr.ZScore(GetKeyPostScoreZSet(), member); score != 100 {
+		t.Errorf("score = %v, want 100", score)
+	}
+	if mr.Exists(GetKeyVotePostNumHash(1002)) {
+		t.Error("vote num hash should not be created for expired post")
+	}
+}
+
+// TestRecountVoteNums 按用户投票重新统计计数,已删除帖子的计数保留
+func TestRecountVoteNums(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 150; i++ {
+		mr.ZAdd(GetKeyPostTimeZSet(), float64(i), strconv.Itoa(i))
+	}
+	mr.HSet(GetKeyVotePostHash(1), "10", "1", "11", "1", "12", "-1")
+	mr.HSet(GetKeyVotePostNumHash(1), VoteNumFieldUp, "5", VoteNumFieldDown, "0")
+	mr.HSet(GetKeyVotePostNumHash(2), VoteNumFieldUp, "3", VoteNumFieldDown, "1")
+	mr.HSet(GetKeyVotePostHash(150), "10", "-1")
+	// 帖子200已删除
+	mr.HSet(GetKeyVotePostNumHash(200), VoteNumFieldUp, "4", VoteNumFieldDown, "0")
+
+	if err := RecountVoteNums(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64][2]string{1: {"2", "1"}, 2: {"0", "0"}, 150: {"0", "1"}, 200: {"4", "0"}}
+	for postID, nums := range want {
+		key := GetKeyVotePostNumHash(postID)
+		if up, down := mr.HGet(key, VoteNumFieldUp), mr.HGet(key, VoteNumFieldDown); up != nums[0] || down != nums[1] {
+			t.Errorf("post %d = %s/%s, want %s/%s", postID, up, down, nums[0], nums[1])
+		}
+	}
+}
+
+// TestRecountVoteNumsArchived 超出投票期限且用户投票已归档删除的帖子保留计数
+func TestRecountVoteNumsArchived(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.ZAdd(GetKeyPostTimeZSet(), 100, "1") // 已归档
+	mr.ZAdd(GetKeyPostTimeZSet(), 150, "2") // 超出期限未归档
+	mr.ZAdd(GetKeyPostTimeZSet(), 300, "3") // 投票期限内没有投票
+	mr.HSet(GetKeyVotePostNumHash(1), VoteNumFieldUp, "7", VoteNumFieldDown, "2")
+	mr.HSet(GetKeyVotePostHash(2), "10", "1")
+	mr.HSet(GetKeyVotePostNumHash(3), VoteNumFieldUp, "1", VoteNumFieldDown, "0")
+
+	if err := RecountVoteNums(ctx, 200); err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64][2]string{1: {"7", "2"}, 2: {"1", "0"}, 3: {"0", "0"}}
+	for postID, nums := range want {
+		key := GetKeyVotePostNumHash(postID)
+		if up, down := mr.HGet(key, VoteNumFieldUp), mr.HGet(key, VoteNumFieldDown); up != nums[0] || down != nums[1] {
+			t.Errorf("post %d = %s/%s, want %s/%s", postID, up, down, nums[0], nums[1])
+		}
+	}
+}
+
+// TestVoteForPostNotExist 不在时间ZSet中的帖子(已删除或不存在)不能投票,也不写入投票和计数
+func TestVoteForPostNotExist(t *testing.T) {
+	mr := setupMiniRedis(t)
+	ctx := context.Background()
+
+	for _, deadline := range []int64{0, 200} {
+		_, err := VoteForPost(ctx, 432, deadline, &models.VotePost{PostID: 1003, UserID: 1, VoteType: 1})
+		if err != ErrorPostNotExist {
+			t.Fatalf("deadline %d: err = %v, want %v", deadline, err, ErrorPostNotExist)
+		}
+	}
+	if mr.Exists(GetKeyVotePostHash(1003)) || mr.Exists(GetKeyVotePostNumHash(1003)) {
+		t.Error("votes should not be written for a post that does not exist")
+	}
+}
@@ -15,6 +15,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "以JWKS格式返回所有非对称签名密钥的公钥,其他服务可根据token头部的kid验证Lightning的AccessToken",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取JWT公钥集合",
+                "responses": {
+                    "200": {
+                        "description": "成功返回公钥集合",
+                        "schema": {
+                            "$ref": "#/definitions/jwt.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/add/community": {
             "post": {
                 "description": "创建一个新的社区",
@@ -61,56 +81,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/community": {
-            "get": {
-                "description": "获取所有社区的列表",
-                "produces": [
-                    "application/json"
+        "/admin/add/community/moderator/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
-                "tags": [
-                    "社区相关接口"
+                "description": "管理员指派用户为社区版主",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "获取社区列表",
-                "responses": {
-                    "200": {
-                        "description": "成功返回社区列表",
-                        "schema": {
-                            "$ref": "#/definitions/controller._ResponseCommunityList"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器繁忙",
-                        "schema": {
-                            "$ref": "#/definitions/controller._Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v2/community/{id}": {
-            "get": {
-                "description": "根据社区ID获取社区的详细信息",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "社区相关接口"
+                    "权限相关接口"
                 ],
-                "summary": "获取社区详情",
+                "summary": "指派社区版主",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
                     {
                         "type": "string",
                         "description": "社区ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "版主参数",
+                        "name": "moderator",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamModerator"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功返回社区详情",
+                        "description": "指派成功",
                         "schema": {
-                            "$ref": "#/definitions/controller._ResponseCommunityDetail"
+                            "$ref": "#/definitions/controller._Response"
                         }
                     },
                     "400": {
@@ -119,8 +136,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
                     "404": {
-                        "description": "社区不存在",
+                        "description": "社区或用户不存在",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -134,33 +157,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/login": {
-            "post": {
-                "description": "用户登录接口",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/archive/community/{id}": {
+            "put": {
+                "description": "归档社区,归档后社区不出现在社区列表中且不能发帖",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户相关接口"
+                    "社区相关接口"
                 ],
-                "summary": "用户登录",
+                "summary": "归档社区",
                 "parameters": [
                     {
-                        "description": "登录参数",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ParamLogin"
-                        }
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功，返回 accessToken",
+                        "description": "成功归档社区",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -171,8 +189,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "401": {
-                        "description": "用户名或密码错误",
+                    "404": {
+                        "description": "社区不存在",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -186,24 +204,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/post": {
-            "post": {
+        "/admin/delete/community/moderator/{id}/{user_id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "创建一个新的帖子",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "管理员取消用户的社区版主身份",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "帖子相关接口"
+                    "权限相关接口"
                 ],
-                "summary": "创建帖子",
+                "summary": "取消社区版主",
                 "parameters": [
                     {
                         "type": "string",
@@ -212,18 +227,23 @@ const docTemplate = `{
                         "in": "header"
                     },
                     {
-                        "description": "帖子参数",
-                        "name": "post",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ParamPost"
-                        }
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建帖子",
+                        "description": "取消成功",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -234,8 +254,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "401": {
-                        "description": "用户未登录",
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -249,42 +275,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/post/{id}": {
+        "/admin/list/community": {
             "get": {
-                "description": "根据帖子ID获取帖子详情",
+                "description": "获取包括已归档社区在内的所有社区及其帖子数量",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "帖子相关接口"
-                ],
-                "summary": "获取帖子详情",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "帖子ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "社区相关接口"
                 ],
+                "summary": "管理员获取社区列表",
                 "responses": {
                     "200": {
-                        "description": "成功返回帖子详情",
-                        "schema": {
-                            "$ref": "#/definitions/controller._ResponsePostDetail"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/controller._Response"
-                        }
-                    },
-                    "404": {
-                        "description": "帖子不存在",
+                        "description": "成功返回社区列表",
                         "schema": {
-                            "$ref": "#/definitions/controller._Response"
+                            "$ref": "#/definitions/controller._ResponseAdminCommunityList"
                         }
                     },
                     "500": {
@@ -296,46 +301,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/posts": {
-            "get": {
-                "description": "获取按时间或分数排序的帖子列表",
+        "/admin/rebuild/karma": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员从数据库投票表重新统计所有作者的karma并写入mysql和redis",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "帖子相关接口"
+                    "用户相关接口"
                 ],
-                "summary": "获取帖子列表",
+                "summary": "重建用户karma",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "排序方式(time或score)",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "pageToken",
-                        "name": "token",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "社区ID",
-                        "name": "community_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功返回pageToken和帖子列表",
+                        "description": "重建成功",
                         "schema": {
-                            "$ref": "#/definitions/controller._ResponsePosts"
+                            "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -349,39 +352,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/signup": {
-            "post": {
-                "description": "用户注册接口",
-                "consumes": [
-                    "application/json"
+        "/admin/rebuild/vote/num": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "管理员按redis中的用户投票重新统计所有帖子的赞成票和反对票数量,已归档帖子从归档表恢复",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户相关接口"
+                    "投票相关接口"
                 ],
-                "summary": "用户注册",
+                "summary": "重建帖子投票数量",
                 "parameters": [
                     {
-                        "description": "注册参数",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ParamSignUp"
-                        }
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "注册成功",
+                        "description": "重建成功",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -395,14 +403,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v2/vote": {
-            "post": {
+        "/admin/set/user/role/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "用户可以对帖子进行投票（点赞或点踩）",
+                "description": "管理员设置用户为管理员或普通用户,角色变更在用户刷新token后生效",
                 "consumes": [
                     "application/json"
                 ],
@@ -410,9 +418,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "投票相关接口"
+                    "权限相关接口"
                 ],
-                "summary": "给帖子投票",
+                "summary": "设置用户角色",
                 "parameters": [
                     {
                         "type": "string",
@@ -421,18 +429,25 @@ const docTemplate = `{
                         "in": "header"
                     },
                     {
-                        "description": "投票参数",
-                        "name": "vote",
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色参数",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ParamVoteForPost"
+                            "$ref": "#/definitions/models.ParamSetUserRole"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "投票成功",
+                        "description": "设置成功",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -443,14 +458,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "401": {
-                        "description": "用户未登录",
+                    "403": {
+                        "description": "没有权限",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
                     },
-                    "403": {
-                        "description": "重复投票",
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/controller._Response"
                         }
@@ -463,10 +478,2055 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.ResCode": {
+        },
+        "/admin/unarchive/community/{id}": {
+            "put": {
+                "description": "取消社区的归档状态",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "取消归档社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功取消归档社区",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/update/community/{id}": {
+            "put": {
+                "description": "管理员或社区版主修改社区的名称和简介",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "编辑社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "编辑社区参数",
+                        "name": "community",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamUpdateCommunity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功编辑社区",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "409": {
+                        "description": "社区名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/community": {
+            "get": {
+                "description": "获取所有社区的列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "获取社区列表",
+                "responses": {
+                    "200": {
+                        "description": "成功返回社区列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseCommunityList"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/community/{id}": {
+            "get": {
+                "description": "根据社区ID获取社区的详细信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "获取社区详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回社区详情",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseCommunityDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/community/{id}/join": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "当前用户加入社区,已经是成员时忽略",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "加入社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功加入社区",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或社区已归档",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "当前用户退出社区,不是成员时忽略",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "退出社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功退出社区",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/feed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "合并当前用户加入的社区的帖子,按时间或分数排序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "获取首页信息流",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方式(time、score、gravity、hot或best),默认score",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pageToken,使用返回的next_token或prev_token",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回pageToken和帖子列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponsePosts"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/leaderboard/users": {
+            "get": {
+                "description": "获取全站或社区中帖子获得的赞成票减反对票最多的用户",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取karma排行榜",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "社区ID,不填时获取全站排行榜",
+                        "name": "community_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回的用户数量,默认10,最多100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回排行榜",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseKarmaLeaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/login": {
+            "post": {
+                "description": "用户登录接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录参数",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamLogin"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功，返回 accessToken",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户名或密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "撤销当前会话,当前会话的AccessToken和RefreshToken立即失效,不影响其他设备",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "退出登录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功退出登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的邮箱、性别、简介和头像",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取我的资料",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回个人资料",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseMyProfile"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "修改当前用户的邮箱、性别、简介和头像,未填写的字段会被清空",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "编辑我的资料",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "个人资料参数",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamUpdateProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功编辑个人资料",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/me/communities": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户加入的未归档社区列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "获取我加入的社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回社区列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseCommunityList"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "校验原密码后设置新密码,新密码需要满足密码策略,修改后其他设备上的会话失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "修改密码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "修改密码参数",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamChangePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功修改密码",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或原密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/me/verify-email": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "向当前用户资料中的邮箱重新发送验证邮件",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "重新发送验证邮件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功发送验证邮件",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "没有设置邮箱或邮箱已验证",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/mod/community/{id}": {
+            "put": {
+                "description": "管理员或社区版主修改社区的名称和简介",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "社区相关接口"
+                ],
+                "summary": "编辑社区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "编辑社区参数",
+                        "name": "community",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamUpdateCommunity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功编辑社区",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "409": {
+                        "description": "社区名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/mod/community/{id}/post/{post_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "管理员或社区版主删除社区中的帖子(软删除)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "版主删除帖子",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "社区ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "post_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功删除帖子",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/password/reset": {
+            "post": {
+                "description": "使用邮件中的token设置新密码,token只能使用一次,重置后用户的所有会话失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "重置密码",
+                "parameters": [
+                    {
+                        "description": "重置密码参数",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamResetPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功重置密码",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/password/reset/request": {
+            "post": {
+                "description": "向用户的邮箱发送重置密码token,用户不存在或没有设置邮箱时同样返回成功",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "申请重置密码",
+                "parameters": [
+                    {
+                        "description": "申请重置密码参数",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamRequestPasswordReset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已受理",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/post": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "创建一个新的帖子",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "创建帖子",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "帖子参数",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamPost"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建帖子",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "社区已归档或邮箱未验证",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "社区不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/post/{id}": {
+            "get": {
+                "description": "根据帖子ID获取帖子详情",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "获取帖子详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回帖子详情",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponsePostDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "作者编辑自己帖子的标题和内容",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "编辑帖子",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "编辑帖子参数",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamUpdatePost"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功编辑帖子",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "作者删除自己的帖子(软删除)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "删除帖子",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功删除帖子",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/post/{id}/comments": {
+            "get": {
+                "description": "按发表时间获取帖子下的评论,指定parent_id时获取该评论的回复",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论相关接口"
+                ],
+                "summary": "获取评论列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "父评论ID",
+                        "name": "parent_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pageToken",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回pageToken和评论列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseComments"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "在帖子下发表评论,或回复帖子下的其他评论",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论相关接口"
+                ],
+                "summary": "发表评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "评论参数",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamComment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功发表评论",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子或评论不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/posts": {
+            "get": {
+                "description": "获取社区内或全站按时间或分数排序的帖子列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "获取帖子列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "排序方式(time、score、gravity、hot或best),默认score",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pageToken,使用返回的next_token或prev_token",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "社区ID,不填时获取全站帖子",
+                        "name": "community_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "获取全站帖子时是否排除已归档社区的帖子",
+                        "name": "exclude_archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回pageToken和帖子列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponsePosts"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户所有未过期的登录会话,current标记当前请求使用的会话",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取登录会话列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回登录会话列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseSessions"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "撤销当前用户的所有登录会话,包括当前会话",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "退出所有设备",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功撤销所有会话",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "撤销当前用户的一个登录会话,该会话的token立即失效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "撤销登录会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功撤销会话",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "登录会话不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/signup": {
+            "post": {
+                "description": "用户注册接口,填写邮箱时发送验证邮件,账号以邮箱未验证状态创建",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册参数",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamSignUp"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或邮箱已被使用",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户关注的人发布的帖子,按发帖时间倒序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "帖子相关接口"
+                ],
+                "summary": "获取关注时间线",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pageToken,使用返回的next_token或prev_token",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回pageToken和帖子列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponsePosts"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/token/refresh": {
+            "post": {
+                "description": "使用Cookie中的RefreshToken换取新的AccessToken,同时轮换RefreshToken Cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "刷新AccessToken",
+                "responses": {
+                    "200": {
+                        "description": "成功返回新的accessToken",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未登录或RefreshToken无效",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/user/{id}": {
+            "get": {
+                "description": "获取用户的公开资料、帖子数量、karma、粉丝数量和关注的人数",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取用户主页",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户主页信息",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponseUserProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/user/{id}/follow": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "关注用户,已经关注时忽略,关注后该用户发布的帖子出现在时间线中",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "关注用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "被关注的用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功关注用户",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "取消关注用户,没有关注时忽略",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "取消关注用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "被关注的用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功取消关注",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/user/{id}/posts": {
+            "get": {
+                "description": "获取用户发布的帖子,按发帖时间倒序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "获取用户的帖子",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pageToken,使用返回的next_token或prev_token",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回pageToken和帖子列表",
+                        "schema": {
+                            "$ref": "#/definitions/controller._ResponsePosts"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/verify-email": {
+            "post": {
+                "description": "使用验证邮件中的token激活邮箱,token只能使用一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户相关接口"
+                ],
+                "summary": "验证邮箱",
+                "parameters": [
+                    {
+                        "description": "验证邮箱参数",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamVerifyEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功验证邮箱",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/vote": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "用户可以对帖子进行投票（点赞或点踩）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "投票相关接口"
+                ],
+                "summary": "给帖子投票",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer 用户令牌",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "投票参数",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParamVoteForPost"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "投票成功",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未登录",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "403": {
+                        "description": "重复投票、投票时间已过或邮箱未验证",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "404": {
+                        "description": "帖子不存在",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器繁忙",
+                        "schema": {
+                            "$ref": "#/definitions/controller._Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.ResCode": {
             "type": "integer",
             "enum": [
                 1000,
@@ -481,7 +2541,19 @@ const docTemplate = `{
                 1009,
                 1010,
                 1011,
-                1012
+                1012,
+                1013,
+                1014,
+                1015,
+                1016,
+                1017,
+                1018,
+                1019,
+                1020,
+                1021,
+                1022,
+                1023,
+                1024
             ],
             "x-enum-varnames": [
                 "CodeSuccess",
@@ -496,7 +2568,19 @@ const docTemplate = `{
                 "CodePostNotExists",
                 "CodeVoteRepeated",
                 "CodeInvalidPageToken",
-                "CodeServerBusy"
+                "CodeServerBusy",
+                "CodeNoPermission",
+                "CodeCommunityNameExists",
+                "CodeCommunityArchived",
+                "CodeUserNotExists",
+                "CodeCommentNotExists",
+                "CodeVoteTimeExpired",
+                "CodeSessionNotExists",
+                "CodeWrongPassword",
+                "CodeInvalidResetToken",
+                "CodeEmailExist",
+                "CodeEmailNotVerified",
+                "CodeInvalidVerifyToken"
             ]
         },
         "controller._Response": {
@@ -519,6 +2603,55 @@ const docTemplate = `{
                 }
             }
         },
+        "controller._ResponseAdminCommunityList": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "社区列表data",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApiCommunityAdmin"
+                    }
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "controller._ResponseComments": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "评论列表和pageToken",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CommentsAndToken"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
         "controller._ResponseCommunityDetail": {
             "type": "object",
             "properties": {
@@ -568,6 +2701,55 @@ const docTemplate = `{
                 }
             }
         },
+        "controller._ResponseKarmaLeaderboard": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "排行榜",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApiUserKarma"
+                    }
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "controller._ResponseMyProfile": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "个人资料",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UserProfile"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
         "controller._ResponsePostDetail": {
             "type": "object",
             "properties": {
@@ -587,34 +2769,190 @@ const docTemplate = `{
                         }
                     ]
                 },
-                "message": {
-                    "description": "提示信息",
-                    "type": "string"
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "controller._ResponsePosts": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "帖子列表和pageToken",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PostsAndToken"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "controller._ResponseSessions": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "登录会话列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Session"
+                    }
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "controller._ResponseUserProfile": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务响应状态码",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResCode"
+                        }
+                    ]
+                },
+                "data": {
+                    "description": "用户主页信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ApiUserProfile"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                }
+            }
+        },
+        "jwt.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "EdDSA曲线",
+                    "type": "string"
+                },
+                "e": {
+                    "description": "RSA指数",
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "RSA模数",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "description": "EdDSA公钥",
+                    "type": "string"
+                }
+            }
+        },
+        "jwt.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwt.JSONWebKey"
+                    }
+                }
+            }
+        },
+        "models.ApiComment": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "author_name": {
+                    "type": "string"
+                },
+                "comment_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "0表示直接回复帖子",
+                    "type": "string",
+                    "example": "0"
+                },
+                "post_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "reply_num": {
+                    "description": "回复该评论的评论数量",
+                    "type": "integer"
                 }
             }
         },
-        "controller._ResponsePosts": {
+        "models.ApiCommunityAdmin": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "业务响应状态码",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/controller.ResCode"
-                        }
-                    ]
+                "community_id": {
+                    "type": "integer"
                 },
-                "data": {
-                    "description": "帖子列表和pageToken",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.PostsAndToken"
-                        }
-                    ]
+                "community_name": {
+                    "type": "string"
                 },
-                "message": {
-                    "description": "提示信息",
+                "create_time": {
+                    "type": "string"
+                },
+                "introduction": {
                     "type": "string"
+                },
+                "member_num": {
+                    "description": "社区成员数量",
+                    "type": "integer"
+                },
+                "post_num": {
+                    "description": "社区中未删除的帖子数量",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
                 }
             }
         },
@@ -628,6 +2966,9 @@ const docTemplate = `{
                 "author_name": {
                     "type": "string"
                 },
+                "comment_num": {
+                    "type": "integer"
+                },
                 "community": {
                     "$ref": "#/definitions/models.CommunityDetail"
                 },
@@ -641,6 +2982,9 @@ const docTemplate = `{
                 "create_time": {
                     "type": "string"
                 },
+                "down_num": {
+                    "type": "integer"
+                },
                 "post_id": {
                     "type": "string",
                     "example": "0"
@@ -652,8 +2996,78 @@ const docTemplate = `{
                 "title": {
                     "type": "string"
                 },
+                "up_num": {
+                    "type": "integer"
+                },
                 "vote_num": {
+                    "description": "赞成票减反对票",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ApiUserKarma": {
+            "type": "object",
+            "properties": {
+                "karma": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApiUserProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "follower_num": {
+                    "description": "粉丝数量",
+                    "type": "integer"
+                },
+                "following_num": {
+                    "description": "关注的人数",
+                    "type": "integer"
+                },
+                "gender": {
+                    "type": "integer"
+                },
+                "karma": {
+                    "description": "发布的帖子获得的赞成票减反对票",
+                    "type": "integer"
+                },
+                "post_num": {
+                    "description": "发布的帖子数量",
                     "type": "integer"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentsAndToken": {
+            "type": "object",
+            "properties": {
+                "comment_list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApiComment"
+                    }
+                },
+                "token": {
+                    "type": "string"
                 }
             }
         },
@@ -682,6 +3096,49 @@ const docTemplate = `{
                 },
                 "introduction": {
                     "type": "string"
+                },
+                "member_num": {
+                    "description": "社区成员数量",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ParamChangePassword": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password",
+                "re_new_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                },
+                "re_new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParamComment": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "parent_id": {
+                    "description": "回复的评论ID,不填表示直接回复帖子",
+                    "type": "string",
+                    "example": "0"
                 }
             }
         },
@@ -722,6 +3179,19 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ParamModerator": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "description": "用户ID",
+                    "type": "string",
+                    "example": "7549250837680128"
+                }
+            }
+        },
         "models.ParamPost": {
             "type": "object",
             "required": [
@@ -742,6 +3212,52 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ParamRequestPasswordReset": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "juelan"
+                }
+            }
+        },
+        "models.ParamResetPassword": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "re_new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "re_new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "邮件中的重置密码token",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParamSetUserRole": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "用户角色{0:普通用户,2:管理员}",
+                    "type": "string",
+                    "enum": [
+                        0,
+                        2
+                    ],
+                    "example": "2"
+                }
+            }
+        },
         "models.ParamSignUp": {
             "type": "object",
             "required": [
@@ -750,6 +3266,12 @@ const docTemplate = `{
                 "username"
             ],
             "properties": {
+                "email": {
+                    "description": "配置要求时必须填写",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "gopher@example.com"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -761,6 +3283,76 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ParamUpdateCommunity": {
+            "type": "object",
+            "required": [
+                "community_name",
+                "introduction"
+            ],
+            "properties": {
+                "community_name": {
+                    "type": "string"
+                },
+                "introduction": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParamUpdatePost": {
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParamUpdateProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "bio": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "gopher@example.com"
+                },
+                "gender": {
+                    "description": "性别{0:未知,1:男,2:女}",
+                    "type": "integer",
+                    "enum": [
+                        0,
+                        1,
+                        2
+                    ],
+                    "example": 1
+                }
+            }
+        },
+        "models.ParamVerifyEmail": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "邮件中的验证token",
+                    "type": "string"
+                }
+            }
+        },
         "models.ParamVoteForPost": {
             "type": "object",
             "required": [
@@ -787,13 +3379,74 @@ const docTemplate = `{
         "models.PostsAndToken": {
             "type": "object",
             "properties": {
+                "has_more": {
+                    "description": "是否还有下一页",
+                    "type": "boolean"
+                },
+                "next_token": {
+                    "description": "下一页的pageToken,没有下一页时为空",
+                    "type": "string"
+                },
                 "post_list": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.ApiPostDetail"
                     }
                 },
-                "token": {
+                "prev_token": {
+                    "description": "上一页的pageToken,没有上一页时为空",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Session": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "是否为当前请求使用的会话",
+                    "type": "boolean"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_active_time": {
+                    "description": "最近一次刷新AccessToken的时间",
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "gender": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "username": {
                     "type": "string"
                 }
             }
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"web_app/models"
+
+	"go.uber.org/zap"
+)
+
+// Canal 事件类型
+const (
+	CanalTypeInsert = "INSERT"
+	CanalTypeUpdate = "UPDATE"
+	CanalTypeDelete = "DELETE"
+)
+
+// Canal 同步的数据表
+const (
+	TableCommunity = "community"
+	TablePost      = "post"
+)
+
+// canalHandler 处理Canal消息中的一行数据,old为该行被修改字段的旧值,只有UPDATE事件才有
+type canalHandler func(ctx context.Context, data, old map[string]interface{}) error
+
+// canalHandlers 按 表名->事件类型 注册的处理函数
+var canalHandlers = map[string]map[string]canalHandler{
+	TableCommunity: {
+		CanalTypeInsert: insertCommunityInRedis,
+		CanalTypeUpdate: updateCommunityInRedis,
+		CanalTypeDelete: deleteCommunityInRedis,
+	},
+	TablePost: {
+		CanalTypeInsert: insertPostInRedis,
+		CanalTypeUpdate: updatePostInRedis,
+		CanalTypeDelete: deletePostInRedis,
+	},
+}
+
+// dispatchCanalMessage 根据表名和事件类型将消息中的每一行数据交给对应的处理函数
+// 没有注册处理函数的消息直接忽略,由调用方提交偏移量
+func dispatchCanalMessage(ctx context.Context, msg *models.CanalMessage) (err error) {
+	if msg.IsDdl {
+		return nil
+	}
+	handler, ok := canalHandlers[msg.Table][msg.Type]
+	if !ok {
+		zap.L().Debug("no handler for canal message",
+			zap.String("table", msg.Table),
+			zap.String("type", msg.Type),
+		)
+		return nil
+	}
+	for i, data := range msg.Data {
+		var old map[string]interface{}
+		if i < len(msg.Old) {
+			old = msg.Old[i]
+		}
+		if err = handler(ctx, data, old); err != nil {
+			zap.L().Error("handle canal message failed",
+				zap.String("table", msg.Table),
+				zap.String("type", msg.Type),
+				zap.Int("row", i),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
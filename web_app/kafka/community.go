@@ -11,43 +11,116 @@ import (
 )
 
 // insertCommunityInRedis 将社区数据插入redis
-func insertCommunityInRedis(ctx context.Context, d map[string]interface{}) (err error) {
+func insertCommunityInRedis(ctx context.Context, d, old map[string]interface{}) (err error) {
+	community, err := parseCommunity(d)
+	if err != nil {
+		return err
+	}
+	//社区信息存入redis,Key  lightning:community:<community_id> 社区id存入redis,Key  lightning:community:list
+	if err = redis.CreateCommunityDetail(ctx, community); err != nil {
+		zap.L().Error("redis.CreateCommunityDetail failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// updateCommunityInRedis 将更新后的社区数据写入redis
+func updateCommunityInRedis(ctx context.Context, d, old map[string]interface{}) (err error) {
+	community, err := parseCommunity(d)
+	if err != nil {
+		return err
+	}
+	// 社区id被修改时移除旧id的缓存
+	if oldIDStr, ok := old["community_id"].(string); ok {
+		oldID, err := strconv.ParseInt(oldIDStr, 10, 64)
+		if err != nil {
+			zap.L().Error("strconv.ParseInt failed", zap.String("community_id", oldIDStr), zap.Error(err))
+			return err
+		}
+		if err = redis.DeleteCommunity(ctx, oldID); err != nil {
+			zap.L().Error("redis.DeleteCommunity failed", zap.Int64("community_id", oldID), zap.Error(err))
+			return err
+		}
+	}
+	// 覆盖 lightning:community:<community_id> Hash
+	if err = redis.CreateCommunityDetail(ctx, community); err != nil {
+		zap.L().Error("redis.CreateCommunityDetail failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// deleteCommunityInRedis 社区从数据库中删除时移除redis中的社区数据
+func deleteCommunityInRedis(ctx context.Context, d, old map[string]interface{}) (err error) {
+	community, err := parseCommunity(d)
+	if err != nil {
+		return err
+	}
+	if err = redis.DeleteCommunity(ctx, community.CommunityID); err != nil {
+		zap.L().Error("redis.DeleteCommunity failed", zap.Int64("community_id", community.CommunityID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// parseCommunity 将Canal消息中的一行数据解析为社区结构体
+func parseCommunity(d map[string]interface{}) (community *models.CommunityDetail, err error) {
 	idStr, ok := d["community_id"].(string)
 	if !ok {
 		zap.L().Error("invalid type for community_id")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	name, ok := d["community_name"].(string)
 	if !ok {
 		zap.L().Error("invalid type for community_name")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	introduction, ok := d["introduction"].(string)
 	if !ok {
 		zap.L().Error("invalid type for introduction")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
+	}
+	statusStr, ok := d["status"].(string)
+	if !ok {
+		zap.L().Error("invalid type for status")
+		return nil, ErrorInvalidDataType
 	}
 	createTimeStr, ok := d["create_time"].(string)
 	if !ok {
 		zap.L().Error("invalid type for create_time")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	createTime, err := tool.ParseTime(createTimeStr)
 	if err != nil {
 		zap.L().Error("tool.ParseTime failed", zap.Error(err))
-		return err
+		return nil, err
 	}
-	id, _ := strconv.ParseInt(idStr, 10, 64)
-	community := &models.CommunityDetail{
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.String("community_id", idStr), zap.Error(err))
+		return nil, err
+	}
+	status, err := strconv.ParseInt(statusStr, 10, 8)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.String("status", statusStr), zap.Error(err))
+		return nil, err
+	}
+	// 添加member_num字段之前的消息中没有该字段,默认为0
+	var memberNum int64
+	if memberNumStr, ok := d["member_num"].(string); ok {
+		memberNum, err = strconv.ParseInt(memberNumStr, 10, 64)
+		if err != nil {
+			zap.L().Error("strconv.ParseInt failed", zap.String("member_num", memberNumStr), zap.Error(err))
+			return nil, err
+		}
+	}
+	community = &models.CommunityDetail{
 		CommunityID:   id,
 		CommunityName: name,
 		Introduction:  introduction,
+		Status:        int8(status),
+		MemberNum:     memberNum,
 		CreateTime:    createTime,
 	}
-	//社区信息存入redis,Key  lightning:community:<community_id> 社区id存入redis,Key  lightning:community:list
-	if err = redis.CreateCommunityDetail(ctx, community); err != nil {
-		zap.L().Error("redis.CreateCommunityDetail failed", zap.Error(err))
-		return err
-	}
-	return nil
+	return community, nil
 }
@@ -21,6 +21,7 @@ func newKafkaReader(brokers []string, groupID string, topic string) *kafka.Reade
 }
 
 // ReadCanalMessage 读取从Canal发送给Kafka的消息
+// 消息格式错误时同时返回kafka.Message,便于调用方提交偏移量跳过该消息
 func ReadCanalMessage(ctx context.Context, r *kafka.Reader) (message *models.CanalMessage, m kafka.Message, err error) {
 	m, err = r.ReadMessage(ctx)
 	if errors.Is(err, context.Canceled) {
@@ -34,13 +35,13 @@ func ReadCanalMessage(ctx context.Context, r *kafka.Reader) (message *models.Can
 	message = new(models.CanalMessage)
 	if err = json.Unmarshal(m.Value, message); err != nil {
 		zap.L().Error("failed to unmarshal msg from kafka", zap.Error(err))
-		return nil, kafka.Message{}, err
+		return nil, m, ErrorInvalidMessage
 	}
 	return message, m, nil
 
 }
 
-// readMessageToRedis 从kafka中读取新增的消息写入Redis
+// readMessageToRedis 从kafka中读取Canal消息并同步到Redis
 func readMessageToRedis(ctx context.Context, r *kafka.Reader) (err error) {
 	for {
 		select {
@@ -54,40 +55,39 @@ func readMessageToRedis(ctx context.Context, r *kafka.Reader) (err error) {
 				if errors.Is(err, context.Canceled) {
 					return nil //正常退出
 				}
+				if errors.Is(err, ErrorInvalidMessage) {
+					// 无法解析的消息重试也不会成功,提交偏移量跳过
+					if err = r.CommitMessages(ctx, m); err != nil {
+						zap.L().Error("kafka commitMessages failed", zap.Error(err))
+					}
+					continue
+				}
 				zap.L().Error("ReadCanalMessage failed", zap.Error(err))
 				continue
 			}
+			if msg == nil { // 上下文已取消
+				continue
+			}
 			// 将消息写入Redis
-			if msg.Type == "INSERT" {
-				if r == communityReader {
-					if err = insertCommunityInRedis(ctx, msg.Data[0]); err != nil {
-						zap.L().Error("insert community in redis failed", zap.Error(err))
-						continue // 如果 Redis 写入失败，不提交偏移量
-					}
-				}
-				if r == postReader {
-					if err = insertPostInRedis(ctx, msg.Data[0]); err != nil {
-						zap.L().Error("insertInRedis failed", zap.Error(err))
-						continue // 如果 Redis 写入失败，不提交偏移量
-					}
-				}
-				// 提交 Kafka 消息的偏移量
-				if err = r.CommitMessages(ctx, m); err != nil {
-					zap.L().Error("kafka commitMessages failed", zap.Error(err))
-					continue
-				}
-
+			if err = dispatchCanalMessage(ctx, msg); err != nil {
+				continue // 如果 Redis 写入失败，不提交偏移量
+			}
+			// 提交 Kafka 消息的偏移量
+			if err = r.CommitMessages(ctx, m); err != nil {
+				zap.L().Error("kafka commitMessages failed", zap.Error(err))
+				continue
 			}
 		}
 	}
 }
 
-// readMessageToMysql 将消息存入mysql
-func readMessageToMysql(ctx context.Context, r *kafka.Reader) (err error) {
+// readVoteMessage 读取投票消息并交给handle处理,处理成功后提交偏移量
+// 投票持久化和karma统计使用不同的消费者组读取同一个topic
+func readVoteMessage(ctx context.Context, r *kafka.Reader, handle func(context.Context, *models.VotePost) error) (err error) {
 	for {
 		select {
 		case <-ctx.Done():
-			zap.L().Info("readMessageToMysql stoped")
+			zap.L().Info("readVoteMessage stoped", zap.String("group_id", r.Config().GroupID))
 			return ctx.Err()
 		default:
 			// 读取消息
@@ -107,9 +107,8 @@ func readMessageToMysql(ctx context.Context, r *kafka.Reader) (err error) {
 					zap.L().Error("json.Unmarshal failed", zap.Error(err))
 					continue
 				}
-				// 将投票数据插入mysql
-				if err = insertVoteInMysql(ctx, msg); err != nil {
-					zap.L().Error("insertVoteInMysql failed", zap.Error(err))
+				// 处理投票数据
+				if err = handle(ctx, msg); err != nil {
 					continue
 				}
 				// 提交 Kafka 消息的偏移量
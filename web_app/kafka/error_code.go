@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	ErrorInvalidDataType = errors.New("数据格式错误")
+	ErrorInvalidMessage  = errors.New("消息格式错误")
 )
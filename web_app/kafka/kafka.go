@@ -12,6 +12,7 @@ var (
 	communityReader *kafka.Reader
 	postReader      *kafka.Reader
 	votePostReader  *kafka.Reader
+	karmaReader     *kafka.Reader
 )
 var (
 	VotePostWriter *kafka.Writer
@@ -25,6 +26,7 @@ func Init(ctx context.Context, cfg *settings.KafkaConfig) {
 	communityReader = newKafkaReader(cfg.Brokers, cfg.GroupIDCommunity, cfg.TopicCommunity)
 	postReader = newKafkaReader(cfg.Brokers, cfg.GroupIDPost, cfg.TopicPost)
 	votePostReader = newKafkaReader(cfg.Brokers, cfg.GroupIDVotePost, cfg.TopicVotePost)
+	karmaReader = newKafkaReader(cfg.Brokers, cfg.GroupIDKarma, cfg.TopicVotePost)
 	// 创建 Kafka 生产者
 	VotePostWriter = newKafkaWriter(cfg.Brokers, cfg.TopicVotePost)
 	go func() {
@@ -49,7 +51,19 @@ func Init(ctx context.Context, cfg *settings.KafkaConfig) {
 	}()
 	go func() {
 		defer votePostReader.Close()
-		if err := readMessageToMysql(ctx, votePostReader); err != nil {
+		// 将投票数据存入mysql
+		if err := readVoteMessage(ctx, votePostReader, insertVoteInMysql); err != nil {
+			if ctx.Err() == context.Canceled {
+				zap.L().Info("Kafka consumer stopped gracefully")
+			} else {
+				zap.L().Error("Kafka consumer encountered an error", zap.Error(err))
+			}
+		}
+	}()
+	go func() {
+		defer karmaReader.Close()
+		// 根据投票变化更新作者karma
+		if err := readVoteMessage(ctx, karmaReader, updateKarmaWithRetry); err != nil {
 			if ctx.Err() == context.Canceled {
 				zap.L().Info("Kafka consumer stopped gracefully")
 			} else {
@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	retryMaxAttempts = 8                      // karma消息处理失败后最多尝试的次数
+	retryMinBackoff  = 100 * time.Millisecond // 第一次重试前的等待时间
+	retryMaxBackoff  = 10 * time.Second       // 重试前最长的等待时间
+)
+
+// updateKarmaWithRetry 处理karma消息,暂时性的数据库和redis错误会重试
+// 处理失败时不提交偏移量,但之后的消息提交偏移量时该消息也会被一并确认,不会再次消费,
+// 所以在放弃之前需要在这里重试
+func updateKarmaWithRetry(ctx context.Context, msg *models.VotePost) (err error) {
+	err = retryHandle(ctx, func() error { return updateKarma(ctx, msg) })
+	if err != nil && ctx.Err() == nil {
+		zap.L().Error("updateKarma failed, message skipped",
+			zap.Int64("post_id", msg.PostID),
+			zap.Int64("voter_id", msg.UserID),
+			zap.Int8("vote_type", msg.VoteType),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// retryHandle 执行handle,失败后按指数退避重试,最多尝试retryMaxAttempts次
+// 数据格式错误重试也不会成功,直接返回;ctx取消时返回ctx的错误
+func retryHandle(ctx context.Context, handle func() error) (err error) {
+	backoff := retryMinBackoff
+	for attempt := 1; ; attempt++ {
+		if err = handle(); err == nil || !retryable(err) || attempt >= retryMaxAttempts {
+			return err
+		}
+		zap.L().Warn("handle kafka message failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+	}
+}
+
+// retryable 判断错误是否可能在重试后成功,消息内容无法解析的错误不重试
+func retryable(err error) bool {
+	var (
+		numErr       *strconv.NumError
+		syntaxErr    *json.SyntaxError
+		unmarshalErr *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.Is(err, ErrorInvalidDataType), errors.Is(err, ErrorInvalidMessage),
+		errors.Is(err, context.Canceled),
+		errors.As(err, &numErr), errors.As(err, &syntaxErr), errors.As(err, &unmarshalErr):
+		return false
+	}
+	return true
+}
+
+// updateKarma 将投票计入帖子作者在全站和帖子所属社区的karma
+// 数据库记录每个投票已计入的投票类型,重复消费的消息不会重复计入
+// redis用数据库中的结果覆盖而不做增量,写入redis失败后重新消费时仍会同步
+// 帖子删除后已获得的karma仍保留,删除前发出的投票照常计入,与从投票表重建的结果一致
+func updateKarma(ctx context.Context, msg *models.VotePost) (err error) {
+	post, err := mysql.GetPostIncludeDeleted(ctx, msg.PostID)
+	if errors.Is(err, mysql.ErrorPostNotExist) {
+		return nil
+	}
+	if err != nil {
+		zap.L().Error("mysql.GetPostIncludeDeleted failed", zap.Int64("post_id", msg.PostID), zap.Error(err))
+		return err
+	}
+	err = mysql.ApplyKarmaVote(ctx, post.AuthorID, post.CommunityID, msg)
+	if err != nil {
+		zap.L().Error("mysql.ApplyKarmaVote failed",
+			zap.Int64("user_id", post.AuthorID),
+			zap.Int64("community_id", post.CommunityID),
+			zap.Int64("post_id", msg.PostID),
+			zap.Int64("voter_id", msg.UserID),
+			zap.Error(err),
+		)
+		return err
+	}
+	karmas, err := mysql.GetUserKarma(ctx, post.AuthorID)
+	if err != nil {
+		zap.L().Error("mysql.GetUserKarma failed", zap.Int64("user_id", post.AuthorID), zap.Error(err))
+		return err
+	}
+	if err = redis.SetUserKarma(ctx, post.AuthorID, karmas); err != nil {
+		zap.L().Error("redis.SetUserKarma failed", zap.Int64("user_id", post.AuthorID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// TestRetryHandle 暂时性错误重试到成功或达到次数上限,数据格式错误不重试
+func TestRetryHandle(t *testing.T) {
+	ctx := context.Background()
+	errTransient := errors.New("connection refused")
+
+	calls := 0
+	err := retryHandle(ctx, func() error {
+		if calls++; calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("err = %v, calls = %d, want nil and 3", err, calls)
+	}
+
+	calls = 0
+	_, parseErr := strconv.ParseInt("x", 10, 64)
+	err = retryHandle(ctx, func() error {
+		calls++
+		return parseErr
+	})
+	if err != parseErr || calls != 1 {
+		t.Errorf("err = %v, calls = %d, want parse error and 1", err, calls)
+	}
+
+	// 取消的ctx在第一次失败后停止重试
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	err = retryHandle(cctx, func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("err = %v, calls = %d, want context.Canceled and 1", err, calls)
+	}
+}
@@ -3,78 +3,250 @@ package kafka
 import (
 	"context"
 	"strconv"
+	"web_app/dao/mysql"
 	"web_app/dao/redis"
 	"web_app/models"
+	"web_app/settings"
 	"web_app/tool"
 
 	"go.uber.org/zap"
 )
 
+// postRankRefresher 计算帖子在各排序策略下的分数,由logic在初始化排序策略时设置
+var postRankRefresher func(ctx context.Context, postID int64)
+
+// SetPostRankRefresher 设置帖子写入redis后计算其排序策略分数的函数
+func SetPostRankRefresher(refresher func(ctx context.Context, postID int64)) {
+	postRankRefresher = refresher
+}
+
+// postScoreRestorer 按投票数和评论数恢复帖子的分数,由logic在初始化排序策略时设置
+var postScoreRestorer func(ctx context.Context, postID, createTime int64) error
+
+// SetPostScoreRestorer 设置被删除的帖子恢复后重新计算其分数的函数
+func SetPostScoreRestorer(restorer func(ctx context.Context, postID, createTime int64) error) {
+	postScoreRestorer = restorer
+}
+
 // 将帖子数据插入redis
-func insertPostInRedis(ctx context.Context, msg map[string]interface{}) (err error) {
+func insertPostInRedis(ctx context.Context, msg, old map[string]interface{}) (err error) {
+	post, err := parsePost(msg)
+	if err != nil {
+		return err
+	}
+	// 将帖子存入redis
+	if err = createPost(ctx, post); err != nil {
+		return err
+	}
+	// 推送到粉丝的时间线
+	return fanOutPost(ctx, post)
+}
+
+// fanOutPost 作者粉丝数不超过阈值时将新帖子推送到粉丝的时间线,否则由粉丝读取时间线时拉取
+func fanOutPost(ctx context.Context, post *models.Post) (err error) {
+	cfg := settings.Conf.TimelineConfig
+	followerNum, err := mysql.GetFollowerNum(ctx, post.AuthorID)
+	if err != nil {
+		zap.L().Error("mysql.GetFollowerNum failed", zap.Int64("author_id", post.AuthorID), zap.Error(err))
+		return err
+	}
+	if followerNum == 0 || followerNum > cfg.FanOutThreshold {
+		return nil
+	}
+	followerIDs, err := mysql.GetFollowerIDs(ctx, post.AuthorID)
+	if err != nil {
+		zap.L().Error("mysql.GetFollowerIDs failed", zap.Int64("author_id", post.AuthorID), zap.Error(err))
+		return err
+	}
+	if err = redis.PushTimeline(ctx, post, followerIDs, cfg.MaxLen); err != nil {
+		zap.L().Error("redis.PushTimeline failed",
+			zap.Int64("postID", post.PostID),
+			zap.Int64("author_id", post.AuthorID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// updatePostInRedis 将更新后的帖子数据写入redis,帖子被软删除时移除缓存
+func updatePostInRedis(ctx context.Context, msg, old map[string]interface{}) (err error) {
+	post, err := parsePost(msg)
+	if err != nil {
+		return err
+	}
+	// 通过旧值还原修改前的社区
+	oldCommunityID := post.CommunityID
+	if oldCommunityIDStr, ok := old["community_id"].(string); ok {
+		oldCommunityID, err = strconv.ParseInt(oldCommunityIDStr, 10, 64)
+		if err != nil {
+			zap.L().Error("strconv.ParseInt failed", zap.String("community_id", oldCommunityIDStr), zap.Error(err))
+			return err
+		}
+	}
+	if post.Status == models.PostStatusDeleted {
+		oldPost := *post
+		oldPost.CommunityID = oldCommunityID
+		return deletePost(ctx, &oldPost)
+	}
+	// 被删除的帖子恢复时重新加入排序,并重新推送到粉丝的时间线
+	if _, ok := old["status"]; ok {
+		if err = createPost(ctx, post); err != nil {
+			return err
+		}
+		// createPost 以发帖时间作为分数,恢复删除前已获得的投票和评论分数
+		if postScoreRestorer != nil {
+			if err = postScoreRestorer(ctx, post.PostID, post.CreatTime.Unix()); err != nil {
+				return err
+			}
+		}
+		return fanOutPost(ctx, post)
+	}
+	// 帖子被移动到其他社区
+	if oldCommunityID != post.CommunityID {
+		if err = redis.MovePost(ctx, post, oldCommunityID); err != nil {
+			zap.L().Error("redis.MovePost failed",
+				zap.Int64("postID", post.PostID),
+				zap.Int64("oldCommunityID", oldCommunityID),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	// 覆盖 lightning:post:<post_id> Hash
+	if err = redis.InsertPost(ctx, post); err != nil {
+		zap.L().Error("redis.InsertPost failed",
+			zap.Int64("postID", post.PostID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// deletePostInRedis 帖子从数据库中删除时移除redis中的帖子数据
+func deletePostInRedis(ctx context.Context, msg, old map[string]interface{}) (err error) {
+	post, err := parsePost(msg)
+	if err != nil {
+		return err
+	}
+	return deletePost(ctx, post)
+}
+
+// createPost 将帖子重新写入redis,并加入启用的各排序策略的ZSet
+func createPost(ctx context.Context, post *models.Post) (err error) {
+	if err = redis.CreatePost(ctx, post); err != nil {
+		zap.L().Error("redis.CreatePost failed",
+			zap.Int64("postID", post.PostID),
+			zap.Int64("communityID", post.CommunityID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if postRankRefresher != nil {
+		postRankRefresher(ctx, post.PostID)
+	}
+	return nil
+}
+
+// deletePost 移除redis中的帖子数据,并将帖子从粉丝的时间线中移除
+func deletePost(ctx context.Context, post *models.Post) (err error) {
+	if err = redis.DeletePost(ctx, post); err != nil {
+		zap.L().Error("redis.DeletePost failed",
+			zap.Int64("postID", post.PostID),
+			zap.Int64("communityID", post.CommunityID),
+			zap.Error(err),
+		)
+		return err
+	}
+	// 推送的帖子和关注作者时合并的帖子都可能在粉丝的时间线中
+	followerIDs, err := mysql.GetFollowerIDs(ctx, post.AuthorID)
+	if err != nil {
+		zap.L().Error("mysql.GetFollowerIDs failed", zap.Int64("author_id", post.AuthorID), zap.Error(err))
+		return err
+	}
+	if err = redis.RemoveFromTimelines(ctx, post.PostID, followerIDs); err != nil {
+		zap.L().Error("redis.RemoveFromTimelines failed",
+			zap.Int64("postID", post.PostID),
+			zap.Int64("author_id", post.AuthorID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// parsePost 将Canal消息中的一行数据解析为帖子结构体
+func parsePost(msg map[string]interface{}) (post *models.Post, err error) {
 	idStr, ok := msg["post_id"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for post_id")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	title, ok := msg["title"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for title")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	content, ok := msg["content"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for content")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	authorIDStr, ok := msg["author_id"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for author_id")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	communityIDStr, ok := msg["community_id"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for community_id")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
+	}
+	statusStr, ok := msg["status"].(string)
+	if !ok {
+		zap.L().Error("Invalid type for status")
+		return nil, ErrorInvalidDataType
 	}
 	createTimeStr, ok := msg["create_time"].(string)
 	if !ok {
 		zap.L().Error("Invalid type for create_time")
-		return ErrorInvalidDataType
+		return nil, ErrorInvalidDataType
 	}
 	// 转换数据类型
 	postID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		zap.L().Error("strconv.ParseInt failed")
+		zap.L().Error("strconv.ParseInt failed", zap.String("post_id", idStr), zap.Error(err))
+		return nil, err
 	}
 	authorID, err := strconv.ParseInt(authorIDStr, 10, 64)
 	if err != nil {
-		zap.L().Error("strconv.ParseInt failed")
+		zap.L().Error("strconv.ParseInt failed", zap.String("author_id", authorIDStr), zap.Error(err))
+		return nil, err
 	}
 	communityID, err := strconv.ParseInt(communityIDStr, 10, 64)
 	if err != nil {
-		zap.L().Error("strconv.ParseInt failed")
+		zap.L().Error("strconv.ParseInt failed", zap.String("community_id", communityIDStr), zap.Error(err))
+		return nil, err
+	}
+	status, err := strconv.ParseInt(statusStr, 10, 32)
+	if err != nil {
+		zap.L().Error("strconv.ParseInt failed", zap.String("status", statusStr), zap.Error(err))
+		return nil, err
 	}
 	createTime, err := tool.ParseTime(createTimeStr)
 	if err != nil {
-		zap.L().Error("tool.ParseTime failed")
+		zap.L().Error("tool.ParseTime failed", zap.String("create_time", createTimeStr), zap.Error(err))
+		return nil, err
 	}
-	post := &models.Post{
+	post = &models.Post{
 		PostID:      postID,
 		AuthorID:    authorID,
 		CommunityID: communityID,
+		Status:      int32(status),
 		Title:       title,
 		Content:     content,
 		CreatTime:   createTime,
 	}
-
-	// 将帖子存入redis
-	if err = redis.CreatePost(ctx, post); err != nil {
-		zap.L().Error("redis.CreatePost failed",
-			zap.Int64("postID", postID),
-			zap.String("authorID", authorIDStr),
-		)
-		return err
-	}
-	return nil
+	return post, nil
 }
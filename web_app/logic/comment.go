@@ -0,0 +1,222 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/pkg/bloom"
+	"web_app/pkg/snowflake"
+
+	"go.uber.org/zap"
+)
+
+const (
+	scorePerComment = 216 //每条评论价值216分,相当于半张赞成票
+)
+
+// CreateComment 发表评论业务
+func CreateComment(ctx context.Context, postID, authorID int64, p *models.ParamComment) (err error) {
+	// 查看帖子是否存在
+	if _, err = getPostInMysql(ctx, postID); err != nil {
+		return err
+	}
+	// 回复评论时父评论必须属于同一个帖子
+	if p.ParentID != 0 {
+		parent, err := mysql.GetComment(ctx, p.ParentID)
+		if err != nil {
+			if errors.Is(err, mysql.ErrorCommentNotExist) {
+				return ErrorCommentNotExist
+			}
+			zap.L().Error("mysql.GetComment failed",
+				zap.Int64("comment_id", p.ParentID),
+				zap.Error(err),
+			)
+			return err
+		}
+		if parent.PostID != postID {
+			return ErrorCommentNotExist
+		}
+	}
+	comment := &models.Comment{
+		CommentID: snowflake.GenID(),
+		PostID:    postID,
+		AuthorID:  authorID,
+		ParentID:  p.ParentID,
+		Content:   p.Content,
+	}
+	// 存入数据库
+	if err = mysql.CreateComment(ctx, comment); err != nil {
+		zap.L().Error("mysql.CreateComment failed",
+			zap.Int64("post_id", postID),
+			zap.Int64("author_id", authorID),
+			zap.Error(err),
+		)
+		return err
+	}
+	// 更新评论数量和帖子分数,超出投票期限的帖子分数不再改变,缓存失败不影响评论结果
+	expired, err := redis.CreateComment(ctx, scorePerComment, voteDeadline(), postID)
+	if errors.Is(err, redis.ErrorDataNotFound) {
+		// 评论数量没有缓存,从数据库加载(已包含本条评论)
+		_, err = loadCommentNum(ctx, postID)
+	}
+	if err != nil {
+		zap.L().Error("redis.CreateComment failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+	}
+	// 更新帖子在其他排序策略下的分数
+	if !expired {
+		refreshPostRank(ctx, postID)
+	}
+	return nil
+}
+
+// GetCommentList 获得帖子评论列表业务
+func GetCommentList(ctx context.Context, postID int64, p *models.ParamGetComments) (commentsAndToken *models.CommentsAndToken, err error) {
+	// 查看帖子是否存在,读接口走缓存
+	if !bloom.IsPostIDExist(postID) {
+		return nil, ErrorPostNotExist
+	}
+	post, err := getPostDetailSingleFlight(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status == models.PostStatusDeleted {
+		return nil, ErrorPostNotExist
+	}
+	// 默认第一页开始
+	query := fmt.Sprintf("post_id=%d&parent_id=%d", postID, p.ParentID)
+	pageSize := DefaultPageSize
+	var cursor int64
+	// 解析Token
+	if len(p.Token) > 0 {
+		pageInfo := models.Token(p.Token).Decode(pageTokenSecret())
+		if pageInfo.InValid() || pageInfo.Query != query { //解析结果无效或与当前查询不一致返回错误
+			return nil, ErrorInvalidPageToken
+		}
+		cursor, err = strconv.ParseInt(pageInfo.NextID, 10, 64)
+		if err != nil {
+			return nil, ErrorInvalidPageToken
+		}
+		pageSize = pageInfo.PageSize
+	}
+	// 多查一条用于判断是否还有下一页
+	comments, err := mysql.GetCommentList(ctx, postID, p.ParentID, cursor, pageSize+1)
+	if err != nil {
+		zap.L().Error("mysql.GetCommentList failed",
+			zap.Int64("post_id", postID),
+			zap.Int64("parent_id", p.ParentID),
+			zap.Int64("cursor", cursor),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	var nextPageToken string
+	if len(comments) > int(pageSize) {
+		nextPageInfo := &models.Page{
+			Query:         query,
+			NextID:        strconv.FormatInt(comments[pageSize].CommentID, 10),
+			NextTimeAtUTC: time.Now().Add(PageTokenExpireTime).Unix(),
+			PageSize:      pageSize,
+		}
+		nextPageToken = string(nextPageInfo.Encode(pageTokenSecret()))
+		comments = comments[:pageSize]
+	}
+	// 批量查询作者用户名和回复数量
+	authorIDs := make([]int64, 0, len(comments))
+	commentIDs := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		authorIDs = append(authorIDs, comment.AuthorID)
+		commentIDs = append(commentIDs, comment.CommentID)
+	}
+	authorNames, err := mysql.GetUserNames(ctx, authorIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetUserNames failed", zap.Error(err))
+		return nil, err
+	}
+	replyNums, err := mysql.GetReplyNums(ctx, commentIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetReplyNums failed", zap.Error(err))
+		return nil, err
+	}
+	commentList := make([]*models.ApiComment, 0, len(comments))
+	for _, comment := range comments {
+		commentList = append(commentList, &models.ApiComment{
+			AuthorName: authorNames[comment.AuthorID],
+			ReplyNum:   replyNums[comment.CommentID],
+			Comment:    comment,
+		})
+	}
+	commentsAndToken = &models.CommentsAndToken{
+		Token:       nextPageToken,
+		CommentList: commentList,
+	}
+	return commentsAndToken, nil
+}
+
+// getCommentNum 获得帖子评论数量,缓存中没有时从数据库加载
+func getCommentNum(ctx context.Context, postID int64) (num int64, err error) {
+	num, err = redis.GetCommentNum(ctx, postID)
+	if errors.Is(err, redis.ErrorDataNotFound) {
+		return loadCommentNum(ctx, postID)
+	}
+	return num, err
+}
+
+// getCommentNums 批量获得帖子评论数量,缓存未命中的帖子一次从数据库加载
+func getCommentNums(ctx context.Context, postIDs []int64) (nums map[int64]int64) {
+	nums, err := redis.GetCommentNums(ctx, postIDs)
+	if err != nil {
+		zap.L().Error("redis.GetCommentNums failed", zap.Error(err))
+		nums = make(map[int64]int64, len(postIDs))
+	}
+	missIDs := make([]int64, 0)
+	for _, postID := range postIDs {
+		if _, ok := nums[postID]; !ok {
+			missIDs = append(missIDs, postID)
+		}
+	}
+	if len(missIDs) == 0 {
+		return nums
+	}
+	missNums, err := mysql.GetCommentNums(ctx, missIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetCommentNums failed", zap.Error(err))
+		return nums
+	}
+	// 没有评论的帖子也要缓存0,避免重复回源
+	loaded := make(map[int64]int64, len(missIDs))
+	for _, postID := range missIDs {
+		loaded[postID] = missNums[postID]
+		nums[postID] = missNums[postID]
+	}
+	if err = redis.SetCommentNums(ctx, loaded); err != nil {
+		zap.L().Error("redis.SetCommentNums failed", zap.Error(err))
+	}
+	return nums
+}
+
+// loadCommentNum 从数据库查询帖子评论数量并写入缓存
+func loadCommentNum(ctx context.Context, postID int64) (num int64, err error) {
+	num, err = mysql.GetCommentNum(ctx, postID)
+	if err != nil {
+		zap.L().Error("mysql.GetCommentNum failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	if err = redis.SetCommentNum(ctx, postID, num); err != nil {
+		zap.L().Error("redis.SetCommentNum failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+	}
+	return num, nil
+}
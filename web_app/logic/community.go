@@ -2,6 +2,7 @@ package logic
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"web_app/dao/mysql"
 	"web_app/dao/redis"
@@ -34,6 +35,87 @@ func CreateCommunity(ctx context.Context, p *models.ParamCommunity) (err error)
 	return nil
 }
 
+// UpdateCommunity 编辑社区名称和简介
+func UpdateCommunity(ctx context.Context, communityID int64, p *models.ParamUpdateCommunity) (err error) {
+	// 查看社区是否存在
+	if _, err = getCommunityInMysql(communityID); err != nil {
+		return err
+	}
+	// 查看社区名称是否被其他社区使用
+	if err = mysql.CommunityNameExists(ctx, communityID, p.CommunityName); err != nil {
+		if errors.Is(err, mysql.ErrorCommunityNameExist) {
+			return ErrorCommunityNameExist
+		}
+		zap.L().Error("mysql.CommunityNameExists failed", zap.Int64("community_id", communityID), zap.Error(err))
+		return err
+	}
+	// 更新数据库,缓存通过 binlog->canal->kafka->redis 更新
+	if err = mysql.UpdateCommunity(ctx, communityID, p); err != nil {
+		zap.L().Error("mysql.UpdateCommunity failed", zap.Int64("community_id", communityID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// InitArchivedCommunities 启动时从数据库加载已归档社区,Canal只同步之后的归档状态变化
+func InitArchivedCommunities(ctx context.Context) (err error) {
+	ids, err := mysql.GetArchivedCommunityIDs(ctx)
+	if err != nil {
+		zap.L().Error("mysql.GetArchivedCommunityIDs failed", zap.Error(err))
+		return err
+	}
+	if err = redis.SetArchivedCommunities(ctx, ids); err != nil {
+		zap.L().Error("redis.SetArchivedCommunities failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetCommunityArchived 归档或取消归档社区
+func SetCommunityArchived(ctx context.Context, communityID int64, archived bool) (err error) {
+	// 查看社区是否存在
+	if _, err = getCommunityInMysql(communityID); err != nil {
+		return err
+	}
+	status := models.CommunityStatusNormal
+	if archived {
+		status = models.CommunityStatusArchived
+	}
+	// 更新数据库,缓存通过 binlog->canal->kafka->redis 更新
+	if err = mysql.UpdateCommunityStatus(ctx, communityID, status); err != nil {
+		zap.L().Error("mysql.UpdateCommunityStatus failed",
+			zap.Int64("community_id", communityID),
+			zap.Int8("status", status),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// GetAdminCommunityList 获得包括已归档社区在内的社区列表和帖子数量
+func GetAdminCommunityList(ctx context.Context) (communityList []*models.ApiCommunityAdmin, err error) {
+	communityList, err = mysql.GetCommunityAdminList(ctx)
+	if err != nil {
+		zap.L().Error("mysql.GetCommunityAdminList failed", zap.Error(err))
+		return nil, err
+	}
+	return communityList, nil
+}
+
+// getCommunityInMysql 从数据库中获得社区信息,管理操作以数据库为准
+func getCommunityInMysql(communityID int64) (community *models.CommunityDetail, err error) {
+	community, err = mysql.GetCommunityDetail(communityID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorCommunityNotExist) {
+			return nil, ErrorCommunityNotExist
+		}
+		zap.L().Error("mysql.GetCommunityDetail failed", zap.Int64("community_id", communityID), zap.Error(err))
+		return nil, err
+	}
+	return community, nil
+}
+
 // GetCommunityList 获得社区列表
 func GetCommunityList(ctx context.Context) (communityList []*models.Community, err error) {
 	// 使用singleflight防止缓存击穿
@@ -114,6 +196,30 @@ func GetCommunityDetail(ctx context.Context, id int64) (communityDetail *models.
 	return communityDetail, nil
 }
 
+// getCommunityDetails 批量获得社区信息,缓存未命中的社区逐个回源,获取失败的社区不在结果中
+func getCommunityDetails(ctx context.Context, communityIDs []int64) (communities map[int64]*models.CommunityDetail) {
+	communities, err := redis.GetCommunityDetails(ctx, communityIDs)
+	if err != nil {
+		zap.L().Error("redis.GetCommunityDetails failed", zap.Error(err))
+		communities = make(map[int64]*models.CommunityDetail, len(communityIDs))
+	}
+	for _, communityID := range communityIDs {
+		if _, ok := communities[communityID]; ok {
+			continue
+		}
+		community, err := GetCommunityDetail(ctx, communityID)
+		if err != nil {
+			zap.L().Error("GetCommunityDetail failed",
+				zap.Int64("community_id", communityID),
+				zap.Error(err),
+			)
+			continue
+		}
+		communities[communityID] = community
+	}
+	return communities
+}
+
 // getCommunityDetailSingleFlight 使用singleFlight获得社区信息
 func getCommunityDetailSingleFlight(ctx context.Context, communityID int64) (communityDetail *models.CommunityDetail, err error) {
 	key := redis.GetKeyCommunityHash(communityID)
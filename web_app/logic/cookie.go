@@ -3,8 +3,10 @@ package logic
 import (
 	"context"
 	"errors"
+	"web_app/dao/mysql"
 	"web_app/dao/redis"
 	"web_app/pkg/jwt"
+	"web_app/settings"
 
 	"go.uber.org/zap"
 )
@@ -16,33 +18,65 @@ const (
 	RefreshCookieHttpOnly = true
 )
 
-// ValidateRefreshToken 认证RefreshToken
-func ValidateRefreshToken(ctx context.Context, refreshToken string) (accessToken string, mc *jwt.MyClaims, err error) {
-	// 解析当前refreshToken
-	mc, err = jwt.ParseToken(refreshToken)
+// ValidateRefreshToken 认证RefreshToken,生成新的accessToken并轮换refreshToken
+// 同一会话轮换出的refreshToken属于同一令牌族,已被轮换的refreshToken在宽限期内再次出现时返回已签发的refreshToken,
+// 超过宽限期视为被盗用,撤销用户的所有会话
+func ValidateRefreshToken(ctx context.Context, refreshToken string) (accessToken, newRefreshToken string, mc *jwt.MyClaims, err error) {
+	// 解析当前refreshToken,accessToken和其他签发者的token不能用于刷新
+	mc, err = jwt.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return "", nil, err
+		zap.L().Warn("jwt.ParseRefreshToken failed", zap.Error(err))
+		return "", "", nil, ErrorInvalidRefeshToken
+	}
+	if mc.ID == "" {
+		return "", "", nil, ErrorInvalidRefeshToken
+	}
+	// 重新获取用户名和角色,使角色变更在刷新accessToken后生效
+	user, err := mysql.GetUserByID(ctx, mc.UserID)
+	if errors.Is(err, mysql.ErrorUserNotFound) {
+		return "", "", nil, ErrorInvalidRefeshToken
 	}
-	// 根据mc中的userID,从redis中查询refreshToken
-	tokenInRedis, err := redis.GetRefreshToken(ctx, mc.UserID)
-	// 在redis中没有找到
 	if err != nil {
-		if errors.Is(err, redis.ErrorRefreshTokenNotFound) {
-			return "", nil, ErrorRefreshTokenNotExist
-		}
-		zap.L().Error("failed to get refreshToken in redis", zap.Int64("userID", mc.UserID), zap.Error(err))
-		return "", nil, err
+		zap.L().Error("failed to get user in mysql", zap.Int64("userID", mc.UserID), zap.Error(err))
+		return "", "", nil, err
 	}
-	// 比较两个token是否一致
-	if tokenInRedis != refreshToken {
-		return "", nil, ErrorInvalidRefeshToken
+	mc.UserInfo, err = newUserInfo(ctx, user)
+	if err != nil {
+		zap.L().Error("failed to get user info", zap.Int64("userID", mc.UserID), zap.Error(err))
+		return "", "", nil, err
 	}
-	// 一致就生成accessToken
-	accessToken, err = genToken(mc.UserID, mc.Username, AccessTokenType)
+	// 生成accessToken和下一代refreshToken
+	accessToken, err = genToken(mc.UserInfo, mc.ID, AccessTokenType)
 	if err != nil {
 		zap.L().Error("failed to generate accessToken", zap.Int64("userID", mc.UserID), zap.Error(err))
-		return "", nil, err
+		return "", "", nil, err
 	}
-	return accessToken, mc, nil
-
+	newRefreshToken, err = jwt.GenRefreshToken(mc.UserInfo, mc.ID, mc.Generation+1, settings.Conf.RefreshTokenDuration)
+	if err != nil {
+		zap.L().Error("failed to generate refreshToken", zap.Int64("userID", mc.UserID), zap.Error(err))
+		return "", "", nil, err
+	}
+	// 与redis中会话保存的refreshToken比较,一致时替换为新的refreshToken
+	newRefreshToken, err = redis.RotateRefreshToken(ctx, mc.UserID, mc.ID, refreshToken, newRefreshToken,
+		settings.Conf.RefreshTokenDuration, settings.Conf.RefreshTokenGrace)
+	if err != nil {
+		// 在redis中没有找到,会话已过期或被撤销
+		if errors.Is(err, redis.ErrorSessionNotFound) {
+			return "", "", nil, ErrorRefreshTokenNotExist
+		}
+		// refreshToken在宽限期之后被重复使用,撤销该用户的所有会话
+		if errors.Is(err, redis.ErrorRefreshTokenRotated) {
+			zap.L().Warn("rotated refresh token reused, revoking all sessions",
+				zap.Int64("user_id", mc.UserID),
+				zap.String("session_id", mc.ID),
+			)
+			if err = RevokeAllSessions(ctx, mc.UserID); err != nil {
+				return "", "", nil, err
+			}
+			return "", "", nil, ErrorRefreshTokenReused
+		}
+		zap.L().Error("redis.RotateRefreshToken failed", zap.Int64("userID", mc.UserID), zap.Error(err))
+		return "", "", nil, err
+	}
+	return accessToken, newRefreshToken, mc, nil
 }
@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/pkg/mailer"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+)
+
+// SendVerifyEmail 向当前用户的邮箱重新发送验证邮件
+func SendVerifyEmail(ctx context.Context, userID int64) (err error) {
+	profile, err := getUserProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if profile.Email == "" {
+		return ErrorEmailRequired
+	}
+	if profile.EmailVerified {
+		return ErrorEmailAlreadyVerified
+	}
+	return sendVerifyEmail(ctx, userID, profile.Username, profile.Email)
+}
+
+// VerifyEmail 使用邮件中的token验证邮箱,token只能使用一次,发送后邮箱被修改时token失效
+func VerifyEmail(ctx context.Context, p *models.ParamVerifyEmail) (err error) {
+	userID, email, err := redis.TakeEmailVerification(ctx, hashSecretToken(p.Token))
+	if err != nil {
+		if errors.Is(err, redis.ErrorDataNotFound) {
+			return ErrorInvalidVerifyToken
+		}
+		zap.L().Error("redis.TakeEmailVerification failed", zap.Error(err))
+		return err
+	}
+	ok, err := mysql.SetEmailVerified(ctx, userID, email)
+	if err != nil {
+		zap.L().Error("mysql.SetEmailVerified failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return ErrorInvalidVerifyToken
+	}
+	return nil
+}
+
+// checkEmailVerified 配置不允许未验证邮箱的用户执行操作时,检查用户的邮箱是否已验证
+func checkEmailVerified(ctx context.Context, userID int64, allowUnverified bool) (err error) {
+	if allowUnverified {
+		return nil
+	}
+	verified, err := mysql.GetEmailVerified(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotFound) {
+			return ErrorUserNotExist
+		}
+		zap.L().Error("mysql.GetEmailVerified failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	if !verified {
+		return ErrorEmailNotVerified
+	}
+	return nil
+}
+
+// sendVerifyEmail 生成邮箱验证token并发送验证邮件
+func sendVerifyEmail(ctx context.Context, userID int64, username, email string) (err error) {
+	token, err := genSecretToken()
+	if err != nil {
+		zap.L().Error("genSecretToken failed", zap.Error(err))
+		return err
+	}
+	duration := settings.Conf.AccountConfig.VerifyTokenDuration
+	if err = redis.CreateEmailVerification(ctx, hashSecretToken(token), userID, email, duration); err != nil {
+		zap.L().Error("redis.CreateEmailVerification failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	msg := &mailer.Message{
+		To:      email,
+		Subject: "Lightning 验证邮箱",
+		Body: fmt.Sprintf("你好 %s,请使用验证token: %s 完成邮箱验证,%d小时内有效。如果不是你本人操作,请忽略这封邮件。",
+			username, token, int(duration.Hours())),
+	}
+	if err = mailer.Send(ctx, msg); err != nil {
+		zap.L().Error("mailer.Send failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -11,7 +11,24 @@ var (
 	ErrorInvalidRefeshToken   = errors.New("invalid refreshToken")
 	ErrorCommunityExist       = errors.New("社区已存在")
 	ErrorCommunityNotExist    = errors.New("社区不存在")
+	ErrorCommunityNameExist   = errors.New("社区名称已存在")
+	ErrorCommunityArchived    = errors.New("社区已归档")
 	ErrorPostNotExist         = errors.New("帖子不存在")
 	ErrorInvalidPageToken     = errors.New("invalid pageToken")
+	ErrorPageSecretTooShort   = errors.New("分页token的签名密钥为空或过短")
+	ErrorInvalidOrder         = errors.New("不支持的排序方式")
+	ErrorInvalidPageSize      = errors.New("每页数量超过最大值")
 	ErrorVoteRepeated         = errors.New("重复投票")
+	ErrorVoteTimeExpired      = errors.New("投票时间已过")
+	ErrorNoPermission         = errors.New("没有权限")
+	ErrorCommentNotExist      = errors.New("评论不存在")
+	ErrorFollowSelf           = errors.New("不能关注自己")
+	ErrorSessionNotExist      = errors.New("登录会话不存在")
+	ErrorRefreshTokenReused   = errors.New("RefreshToken被重复使用")
+	ErrorInvalidResetToken    = errors.New("重置密码token无效或已过期")
+	ErrorEmailExist           = errors.New("邮箱已被使用")
+	ErrorEmailRequired        = errors.New("邮箱不能为空")
+	ErrorEmailAlreadyVerified = errors.New("邮箱已验证")
+	ErrorEmailNotVerified     = errors.New("邮箱未验证")
+	ErrorInvalidVerifyToken   = errors.New("邮箱验证token无效或已过期")
 )
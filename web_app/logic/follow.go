@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+)
+
+// Follow 关注用户,并将其已发布的帖子合并到当前用户的时间线
+func Follow(ctx context.Context, userID, followeeID int64) (err error) {
+	if userID == followeeID {
+		return ErrorFollowSelf
+	}
+	// 查看被关注的用户是否存在
+	if _, err = getUserName(ctx, followeeID); err != nil {
+		return err
+	}
+	if err = mysql.Follow(ctx, userID, followeeID); err != nil {
+		zap.L().Error("mysql.Follow failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err),
+		)
+		return err
+	}
+	// 合并前加载被关注的用户发布的帖子
+	if err = loadAuthorPosts(ctx, followeeID); err != nil {
+		return err
+	}
+	if err = redis.FollowAuthor(ctx, userID, followeeID, settings.Conf.TimelineConfig.MaxLen); err != nil {
+		zap.L().Error("redis.FollowAuthor failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// Unfollow 取消关注用户,并将其帖子从当前用户的时间线中移除
+func Unfollow(ctx context.Context, userID, followeeID int64) (err error) {
+	if err = mysql.Unfollow(ctx, userID, followeeID); err != nil {
+		zap.L().Error("mysql.Unfollow failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if err = redis.UnfollowAuthor(ctx, userID, followeeID); err != nil {
+		zap.L().Error("redis.UnfollowAuthor failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// GetTimeline 获得关注的人发布的帖子列表
+// 粉丝数不超过阈值的作者发帖时推送到时间线,超过阈值的作者的帖子在读取时合并
+func GetTimeline(ctx context.Context, userID int64, p *models.ParamGetTimeline) (postsAndToken *models.PostsAndToken, err error) {
+	query := fmt.Sprintf("user_id=%d", userID)
+	popularAuthorIDs, err := mysql.GetPopularFolloweeIDs(ctx, userID, settings.Conf.TimelineConfig.FanOutThreshold)
+	if err != nil {
+		zap.L().Error("mysql.GetPopularFolloweeIDs failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	// 合并前加载高粉丝作者发布的帖子
+	for _, authorID := range popularAuthorIDs {
+		if err = loadAuthorPosts(ctx, authorID); err != nil {
+			return nil, err
+		}
+	}
+	key, err := redis.GetTimelineKey(ctx, userID, popularAuthorIDs)
+	if err != nil {
+		zap.L().Error("redis.GetTimelineKey failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return getPostPage(ctx, key, query, p.Token, p.PageSize)
+}
@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+
+	"go.uber.org/zap"
+)
+
+const DefaultLeaderboardSize int64 = 10 // karma排行榜默认返回的用户数量
+
+// GetKarmaLeaderboard 获得全站或社区的karma排行榜
+func GetKarmaLeaderboard(ctx context.Context, p *models.ParamGetLeaderboard) (users []*models.ApiUserKarma, err error) {
+	if p.CommunityID != 0 {
+		// 查看社区是否存在
+		if _, err = GetCommunityDetail(ctx, p.CommunityID); err != nil {
+			return nil, err
+		}
+	}
+	size := DefaultLeaderboardSize
+	if p.Limit > 0 {
+		size = p.Limit
+	}
+	karmas, err := redis.GetKarmaLeaderboard(ctx, p.CommunityID, size)
+	if err != nil {
+		zap.L().Error("redis.GetKarmaLeaderboard failed",
+			zap.Int64("community_id", p.CommunityID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	userIDs := make([]int64, 0, len(karmas))
+	for _, karma := range karmas {
+		userIDs = append(userIDs, karma.UserID)
+	}
+	names, err := mysql.GetUserNames(ctx, userIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetUserNames failed", zap.Error(err))
+		return nil, err
+	}
+	users = make([]*models.ApiUserKarma, 0, len(karmas))
+	for _, karma := range karmas {
+		users = append(users, &models.ApiUserKarma{
+			UserID:   karma.UserID,
+			Username: names[karma.UserID],
+			Karma:    karma.Karma,
+		})
+	}
+	return users, nil
+}
+
+// InitUserKarmas 启动时从数据库加载所有作者的karma到redis排行,之后由karma消费者覆盖变化的作者
+func InitUserKarmas(ctx context.Context) (err error) {
+	karmas, err := mysql.GetUserKarmas(ctx)
+	if err != nil {
+		zap.L().Error("mysql.GetUserKarmas failed", zap.Error(err))
+		return err
+	}
+	if err = redis.SetUserKarmas(ctx, karmas); err != nil {
+		zap.L().Error("redis.SetUserKarmas failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RebuildKarma 从数据库的投票表重新统计所有作者的karma并写入mysql和redis
+func RebuildKarma(ctx context.Context) (err error) {
+	karmas, err := mysql.CountUserKarmas(ctx)
+	if err != nil {
+		zap.L().Error("mysql.CountUserKarmas failed", zap.Error(err))
+		return err
+	}
+	if err = mysql.ReplaceUserKarmas(ctx, karmas); err != nil {
+		zap.L().Error("mysql.ReplaceUserKarmas failed", zap.Error(err))
+		return err
+	}
+	if err = redis.SetUserKarmas(ctx, karmas); err != nil {
+		zap.L().Error("redis.SetUserKarmas failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
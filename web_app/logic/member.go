@@ -0,0 +1,97 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+
+	"go.uber.org/zap"
+)
+
+// JoinCommunity 用户加入社区,已归档的社区不能加入
+func JoinCommunity(ctx context.Context, communityID, userID int64) (err error) {
+	// 查看社区是否存在
+	community, err := getCommunityInMysql(communityID)
+	if err != nil {
+		return err
+	}
+	if community.Status == models.CommunityStatusArchived {
+		return ErrorCommunityArchived
+	}
+	// 更新数据库,社区成员数量通过 binlog->canal->kafka->redis 更新
+	if err = mysql.JoinCommunity(ctx, communityID, userID); err != nil {
+		zap.L().Error("mysql.JoinCommunity failed",
+			zap.Int64("community_id", communityID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return err
+	}
+	deleteFeed(ctx, userID)
+	return nil
+}
+
+// LeaveCommunity 用户退出社区
+func LeaveCommunity(ctx context.Context, communityID, userID int64) (err error) {
+	// 查看社区是否存在
+	if _, err = getCommunityInMysql(communityID); err != nil {
+		return err
+	}
+	if err = mysql.LeaveCommunity(ctx, communityID, userID); err != nil {
+		zap.L().Error("mysql.LeaveCommunity failed",
+			zap.Int64("community_id", communityID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return err
+	}
+	deleteFeed(ctx, userID)
+	return nil
+}
+
+// deleteFeed 删除用户合并的帖子列表,删除失败时列表在过期后更新
+func deleteFeed(ctx context.Context, userID int64) {
+	if err := redis.DeleteFeed(ctx, userID); err != nil {
+		zap.L().Warn("redis.DeleteFeed failed", zap.Int64("user_id", userID), zap.Error(err))
+	}
+}
+
+// GetJoinedCommunityList 获得用户加入的社区列表
+func GetJoinedCommunityList(ctx context.Context, userID int64) (communityList []*models.Community, err error) {
+	communityList, err = mysql.GetJoinedCommunityList(ctx, userID)
+	if err != nil {
+		zap.L().Error("mysql.GetJoinedCommunityList failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	if communityList == nil {
+		communityList = make([]*models.Community, 0)
+	}
+	return communityList, nil
+}
+
+// GetFeed 获得用户加入的社区的帖子列表
+func GetFeed(ctx context.Context, userID int64, p *models.ParamGetFeed) (postsAndToken *models.PostsAndToken, err error) {
+	// 校验排序方式
+	if p.Order, err = checkOrder(p.Order); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("user_id=%d&order=%s", userID, p.Order)
+	communityIDs, err := mysql.GetJoinedCommunityIDs(ctx, userID)
+	if err != nil {
+		zap.L().Error("mysql.GetJoinedCommunityIDs failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	// 合并用户加入的社区的帖子
+	key, err := redis.GetFeedKey(ctx, userID, p.Order, communityIDs)
+	if err != nil {
+		zap.L().Error("redis.GetFeedKey failed",
+			zap.Int64("user_id", userID),
+			zap.String("order", p.Order),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return getPostPage(ctx, key, query, p.Token, p.PageSize)
+}
@@ -0,0 +1,151 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"web_app/dao/mysql"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/pkg/mailer"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePassword 修改密码,并撤销用户在其他设备上的会话
+func ChangePassword(ctx context.Context, userID int64, currentSessionID string, p *models.ParamChangePassword) (err error) {
+	hashedPassword, err := mysql.GetUserPassword(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotFound) {
+			return ErrorUserNotExist
+		}
+		zap.L().Error("mysql.GetUserPassword failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	// 校验原密码
+	if err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(p.OldPassword)); err != nil {
+		return ErrorInvalidPassword
+	}
+	if err = updatePassword(ctx, userID, p.NewPassword); err != nil {
+		return err
+	}
+	// 修改密码后之前申请的重置密码token失效
+	if err = redis.DeletePasswordReset(ctx, userID); err != nil {
+		zap.L().Error("redis.DeletePasswordReset failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	// 撤销其他会话,保留当前会话
+	sessions, err := redis.GetSessions(ctx, userID)
+	if err != nil {
+		zap.L().Error("redis.GetSessions failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.SessionID != currentSessionID {
+			sessionIDs = append(sessionIDs, session.SessionID)
+		}
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	if err = redis.RevokeSessions(ctx, userID, sessionIDs, settings.Conf.AccessTokenDuration); err != nil {
+		zap.L().Error("redis.RevokeSessions failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RequestPasswordReset 生成重置密码token并发送到用户的邮箱
+// 用户不存在或没有设置邮箱时同样返回成功,避免泄露用户是否存在
+func RequestPasswordReset(ctx context.Context, p *models.ParamRequestPasswordReset) (err error) {
+	user := new(models.User)
+	if err = mysql.GetUserByUsername(ctx, p.Username, user); err != nil {
+		if errors.Is(err, mysql.ErrorUsernameNotFound) {
+			return nil
+		}
+		zap.L().Error("mysql.GetUserByUsername failed", zap.String("username", p.Username), zap.Error(err))
+		return err
+	}
+	profile, err := mysql.GetUserProfile(ctx, user.UserID)
+	if err != nil {
+		zap.L().Error("mysql.GetUserProfile failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+		return err
+	}
+	if profile.Email == "" {
+		zap.L().Warn("password reset requested without email", zap.Int64("user_id", user.UserID))
+		return nil
+	}
+	// 生成token,redis中只保存token的哈希值,每个用户只有最新的token有效
+	token, err := genSecretToken()
+	if err != nil {
+		zap.L().Error("genSecretToken failed", zap.Error(err))
+		return err
+	}
+	duration := settings.Conf.PasswordConfig.ResetTokenDuration
+	if err = redis.CreatePasswordReset(ctx, hashSecretToken(token), user.UserID, duration); err != nil {
+		zap.L().Error("redis.CreatePasswordReset failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+		return err
+	}
+	msg := &mailer.Message{
+		To:      profile.Email,
+		Subject: "Lightning 重置密码",
+		Body: fmt.Sprintf("你正在重置账号 %s 的密码,重置token为: %s ,%d分钟内有效。如果不是你本人操作,请忽略这封邮件。",
+			user.Username, token, int(duration.Minutes())),
+	}
+	if err = mailer.Send(ctx, msg); err != nil {
+		zap.L().Error("mailer.Send failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ResetPassword 使用重置密码token设置新密码,并撤销用户的所有会话
+func ResetPassword(ctx context.Context, p *models.ParamResetPassword) (err error) {
+	userID, err := redis.TakePasswordReset(ctx, hashSecretToken(p.Token))
+	if err != nil {
+		if errors.Is(err, redis.ErrorDataNotFound) {
+			return ErrorInvalidResetToken
+		}
+		zap.L().Error("redis.TakePasswordReset failed", zap.Error(err))
+		return err
+	}
+	if err = updatePassword(ctx, userID, p.NewPassword); err != nil {
+		return err
+	}
+	return RevokeAllSessions(ctx, userID)
+}
+
+// updatePassword 加密并保存新密码
+func updatePassword(ctx context.Context, userID int64, password string) (err error) {
+	hashedPassword, err := encryptPassword(password)
+	if err != nil {
+		zap.L().Error("encryptPassword failed", zap.Error(err))
+		return err
+	}
+	if err = mysql.UpdateUserPassword(ctx, userID, hashedPassword); err != nil {
+		zap.L().Error("mysql.UpdateUserPassword failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// genSecretToken 生成随机的一次性token,用于重置密码和验证邮箱
+func genSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecretToken 计算一次性token的哈希值,redis中只保存哈希值
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,8 @@ package logic
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 	"web_app/dao/mysql"
@@ -9,18 +11,37 @@ import (
 	"web_app/models"
 	"web_app/pkg/bloom"
 	"web_app/pkg/snowflake"
+	"web_app/settings"
 
 	"go.uber.org/zap"
 )
 
 const (
-	DefaultPageSize     int64         = 2             //默认每页显示数量
+	DefaultPageSize     int64         = 10            //默认每页显示数量
+	DefaultMaxPageSize  int64         = 50            //未配置时每页最多显示数量
 	DefaultCursor       string        = ""            //默认第一篇帖子
 	PageTokenExpireTime time.Duration = 4 * time.Hour //pageToken过期时间
+
+	minPageTokenSecretLength = 32 //分页token签名密钥的最小长度
 )
 
+// pageTokenKey 分页token的签名密钥,启动时由InitPageToken设置
+var pageTokenKey []byte
+
 // CreatePost 创建帖子业务
 func CreatePost(ctx context.Context, p *models.ParamPost, authorID int64) (err error) {
+	// 配置不允许时,邮箱未验证的用户不能发帖
+	if err = checkEmailVerified(ctx, authorID, settings.Conf.AccountConfig.UnverifiedCanPost); err != nil {
+		return err
+	}
+	// 查看社区是否存在,已归档的社区不能发帖
+	community, err := GetCommunityDetail(ctx, p.CommunityID)
+	if err != nil {
+		return err
+	}
+	if community.Status == models.CommunityStatusArchived {
+		return ErrorCommunityArchived
+	}
 	postID := snowflake.GenID()
 
 	post := &models.Post{
@@ -44,6 +65,93 @@ func CreatePost(ctx context.Context, p *models.ParamPost, authorID int64) (err e
 	return nil
 }
 
+// UpdatePost 编辑帖子业务
+func UpdatePost(ctx context.Context, postID, userID int64, p *models.ParamUpdatePost) (err error) {
+	post, err := getAuthorPost(ctx, postID, userID)
+	if err != nil {
+		return err
+	}
+	post.Title = p.Title
+	post.Content = p.Content
+	// 更新数据库,缓存通过 binlog->canal->kafka->redis 更新
+	if err = mysql.UpdatePost(ctx, post); err != nil {
+		zap.L().Error("mysql.UpdatePost failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// DeletePost 删除帖子业务(软删除)
+func DeletePost(ctx context.Context, postID, userID int64) (err error) {
+	if _, err = getAuthorPost(ctx, postID, userID); err != nil {
+		return err
+	}
+	// 更新数据库帖子状态,缓存通过 binlog->canal->kafka->redis 删除
+	if err = mysql.DeletePost(ctx, postID); err != nil {
+		zap.L().Error("mysql.DeletePost failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// ModDeletePost 版主删除所管理社区中的帖子(软删除)
+func ModDeletePost(ctx context.Context, communityID, postID int64) (err error) {
+	post, err := getPostInMysql(ctx, postID)
+	if err != nil {
+		return err
+	}
+	// 帖子不属于该社区时视为不存在
+	if post.CommunityID != communityID {
+		return ErrorPostNotExist
+	}
+	if err = mysql.DeletePost(ctx, postID); err != nil {
+		zap.L().Error("mysql.DeletePost failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// getAuthorPost 从数据库获得帖子,并校验当前用户是否为帖子作者
+func getAuthorPost(ctx context.Context, postID, userID int64) (post *models.Post, err error) {
+	post, err = getPostInMysql(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, ErrorNoPermission
+	}
+	return post, nil
+}
+
+// getPostInMysql 从数据库获得帖子,修改操作以数据库为准,不读缓存
+func getPostInMysql(ctx context.Context, postID int64) (post *models.Post, err error) {
+	// 用布隆过滤器判断帖子id是否存在
+	if !bloom.IsPostIDExist(postID) {
+		return nil, ErrorPostNotExist
+	}
+	post, err = mysql.GetPost(ctx, postID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorPostNotExist) {
+			return nil, ErrorPostNotExist
+		}
+		zap.L().Error("mysql.GetPost failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return post, nil
+}
+
 // GetPostDetail 获得帖子信息业务
 func GetPostDetail(ctx context.Context, postID int64) (postDetail *models.ApiPostDetail, err error) {
 	// 用布隆过滤器判断帖子id是否存在
@@ -56,6 +164,9 @@ func GetPostDetail(ctx context.Context, postID int64) (postDetail *models.ApiPos
 		zap.L().Error("getPostDetailSingleFlight failed", zap.Error(err))
 		return nil, err
 	}
+	if post.Status == models.PostStatusDeleted {
+		return nil, ErrorPostNotExist
+	}
 	// 获取帖子的社区信息
 	community, err := GetCommunityDetail(ctx, post.CommunityID)
 	if err != nil {
@@ -79,11 +190,23 @@ func GetPostDetail(ctx context.Context, postID int64) (postDetail *models.ApiPos
 			zap.Int64("post_id", postID),
 			zap.Error(err),
 		)
+		voteNum = &models.VoteNum{PostID: postID}
+	}
+	// 获取评论数
+	commentNum, err := getCommentNum(ctx, postID)
+	if err != nil {
+		zap.L().Error("getCommentNum failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
 	}
 	// 合并数据
 	postDetail = &models.ApiPostDetail{
 		AuthorName:      authorName,
-		VoteNum:         voteNum,
+		VoteNum:         voteNum.UpNum - voteNum.DownNum,
+		UpNum:           voteNum.UpNum,
+		DownNum:         voteNum.DownNum,
+		CommentNum:      commentNum,
 		Post:            post,
 		CommunityDetail: community,
 	}
@@ -120,7 +243,7 @@ func getPostDetailSingleFlight(ctx context.Context, postID int64) (post *models.
 					zap.Int64("post_id", postID),
 					zap.Error(err),
 				)
-				return nil, err
+				return nil, ErrorPostNotExist
 			}
 			zap.L().Error("mysql.GetPost failed",
 				zap.Int64("post_id", postID),
@@ -147,48 +270,127 @@ func getPostDetailSingleFlight(ctx context.Context, postID int64) (post *models.
 	return post, nil
 }
 
+// InitPageToken 校验并设置分页token的签名密钥,密钥为空或过短时拒绝启动
+func InitPageToken(secret string) error {
+	if len(secret) < minPageTokenSecretLength {
+		return fmt.Errorf("%w: 至少%d字节", ErrorPageSecretTooShort, minPageTokenSecretLength)
+	}
+	pageTokenKey = []byte(secret)
+	return nil
+}
+
+// pageTokenSecret 获得分页token的签名密钥
+func pageTokenSecret() []byte {
+	return pageTokenKey
+}
+
+// maxPageSize 获得每页最多显示数量
+func maxPageSize() int64 {
+	if settings.Conf.MaxPageSize > 0 {
+		return settings.Conf.MaxPageSize
+	}
+	return DefaultMaxPageSize
+}
+
 // GetPostList 获得帖子列表业务
 func GetPostList(ctx context.Context, p *models.ParamGetPostsInOrder) (postsAndToken *models.PostsAndToken, err error) {
+	// 校验排序方式
+	if p.Order, err = checkOrder(p.Order); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("community_id=%d&order=%s&exclude_archived=%t", p.CommunityID, p.Order, p.ExcludeArchived)
+	// 获得帖子列表使用的ZSet
+	key, err := redis.GetPostListKey(ctx, p)
+	if err != nil {
+		zap.L().Error("redis.GetPostListKey failed",
+			zap.Int64("community_id", p.CommunityID),
+			zap.String("order", p.Order),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return getPostPage(ctx, key, query, p.Token, p.PageSize)
+}
+
+// getPostPage 按pageToken获得key对应的ZSet中的一页帖子,query为生成token的查询条件
+func getPostPage(ctx context.Context, key, query, token string, reqPageSize int64) (postsAndToken *models.PostsAndToken, err error) {
 	// 默认第一页开始
 	pageSize := DefaultPageSize
-	cursor := DefaultCursor
+	cursorID := DefaultCursor
+	var cursorScore float64
+	var before bool
 	// 解析Token
-	if len(p.Token) > 0 {
-		pageInfo := models.Token(p.Token).Decode()
-		if pageInfo.InValid() { //解析结果无效返回错误
+	if len(token) > 0 {
+		pageInfo := models.Token(token).Decode(pageTokenSecret())
+		if pageInfo.InValid() || pageInfo.Query != query { //解析结果无效或与当前查询不一致返回错误
 			return nil, ErrorInvalidPageToken
 		}
 		pageSize = pageInfo.PageSize
-		cursor = pageInfo.NextID
+		cursorID = pageInfo.NextID
+		cursorScore = pageInfo.NextScore
+		before = pageInfo.Prev
+	}
+	// 客户端指定的每页数量优先
+	if reqPageSize > 0 {
+		pageSize = reqPageSize
 	}
-	// 在redis中查询帖子ID列表
-	postIDStrs, err := redis.GetPostList(ctx, p, cursor, pageSize+1)
+	if pageSize > maxPageSize() {
+		return nil, ErrorInvalidPageSize
+	}
+	// 在redis中查询游标之后(之前)的帖子ID列表,多查一篇用于判断是否还有下一页(上一页)
+	postIDStrs, scores, err := redis.GetPostList(ctx, key, cursorID, cursorScore, pageSize+1, before)
 	if err != nil {
 		zap.L().Error("redis.GetPostList failed",
-			zap.Int64("community_id", p.CommunityID),
-			zap.String("order", p.Order),
-			zap.String("cursor", cursor),
+			zap.String("key", key),
+			zap.String("cursor_id", cursorID),
+			zap.Float64("cursor_score", cursorScore),
+			zap.Bool("before", before),
 			zap.Error(err),
 		)
 		return nil, err
 	}
 
-	// 判断是否还有下一页
-	var nextPageToken string
-	realPageSize := int(pageSize)
-	if len(postIDStrs) > int(pageSize) {
-		nextPageInfo := &models.Page{
-			NextID:        postIDStrs[pageSize],
-			NextTimeAtUTC: time.Now().Add(PageTokenExpireTime).Unix(),
-			PageSize:      DefaultPageSize,
+	// 判断是否还有上一页和下一页,多查的一篇在远离游标的一端
+	var hasPrev, hasNext bool
+	if before {
+		hasPrev = len(postIDStrs) > int(pageSize)
+		hasNext = true
+		if hasPrev {
+			postIDStrs = postIDStrs[1:]
+			scores = scores[1:]
 		}
-		nextPageToken = string(nextPageInfo.Encode())
 	} else {
-		realPageSize = len(postIDStrs)
+		hasPrev = cursorID != ""
+		hasNext = len(postIDStrs) > int(pageSize)
+		if hasNext {
+			postIDStrs = postIDStrs[:pageSize]
+			scores = scores[:pageSize]
+		}
+	}
+	// 以本页第一篇帖子作为上一页的游标,最后一篇帖子作为下一页的游标
+	newPageToken := func(i int, prev bool) string {
+		pageInfo := &models.Page{
+			Query:         query,
+			Prev:          prev,
+			NextID:        postIDStrs[i],
+			NextScore:     scores[i],
+			NextTimeAtUTC: time.Now().Add(PageTokenExpireTime).Unix(),
+			PageSize:      pageSize,
+		}
+		return string(pageInfo.Encode(pageTokenSecret()))
+	}
+	var prevPageToken, nextPageToken string
+	if len(postIDStrs) > 0 {
+		if hasPrev {
+			prevPageToken = newPageToken(0, true)
+		}
+		if hasNext {
+			nextPageToken = newPageToken(len(postIDStrs)-1, false)
+		}
 	}
-	postIDStrs = postIDStrs[:realPageSize]
-	//通过帖子ID列表查询帖子信息
-	postList := make([]*models.ApiPostDetail, 0, realPageSize)
+	realPageSize := len(postIDStrs)
+	//通过帖子ID列表批量查询帖子信息
+	postIDs := make([]int64, 0, realPageSize)
 	for _, postIDStr := range postIDStrs {
 		postID, err := strconv.ParseInt(postIDStr, 10, 64)
 		if err != nil {
@@ -198,19 +400,100 @@ func GetPostList(ctx context.Context, p *models.ParamGetPostsInOrder) (postsAndT
 			)
 			continue
 		}
-		postDetial, err := GetPostDetail(ctx, postID)
+		postIDs = append(postIDs, postID)
+	}
+	postList, err := getPostDetails(ctx, postIDs)
+	if err != nil {
+		zap.L().Error("getPostDetails failed", zap.Error(err))
+		return nil, err
+	}
+	postsAndToken = &models.PostsAndToken{
+		NextToken: nextPageToken,
+		PrevToken: prevPageToken,
+		HasMore:   nextPageToken != "",
+		PostList:  postList,
+	}
+	return postsAndToken, nil
+}
+
+// getPostDetails 批量获得帖子信息,按postIDs的顺序返回,已删除或不存在的帖子被跳过
+// 帖子、社区、投票数和评论数各用一次管道查询,缓存未命中的帖子和作者用户名各用一次IN查询
+func getPostDetails(ctx context.Context, postIDs []int64) (postList []*models.ApiPostDetail, err error) {
+	// 查缓存,未命中的帖子批量查数据库并回填缓存
+	posts, err := redis.GetPosts(ctx, postIDs)
+	if err != nil {
+		zap.L().Error("redis.GetPosts failed", zap.Error(err))
+		return nil, err
+	}
+	missIDs := make([]int64, 0)
+	for _, postID := range postIDs {
+		if _, ok := posts[postID]; !ok {
+			missIDs = append(missIDs, postID)
+		}
+	}
+	if len(missIDs) > 0 {
+		zap.L().Warn("posts not found in redis", zap.Int64s("post_ids", missIDs))
+		missPosts, err := mysql.GetPosts(ctx, missIDs)
 		if err != nil {
-			zap.L().Error("GetPostDetail failed",
-				zap.Int64("postID", postID),
-				zap.Error(err),
-			)
+			zap.L().Error("mysql.GetPosts failed", zap.Error(err))
+			return nil, err
+		}
+		for _, post := range missPosts {
+			posts[post.PostID] = post
+		}
+		if err = redis.InsertPosts(ctx, missPosts); err != nil {
+			zap.L().Error("redis.InsertPosts failed", zap.Error(err))
+		}
+	}
+	// 按原顺序收集有效帖子及其关联的作者和社区
+	validPosts := make([]*models.Post, 0, len(postIDs))
+	validIDs := make([]int64, 0, len(postIDs))
+	authorIDs := make([]int64, 0, len(postIDs))
+	communityIDs := make([]int64, 0)
+	seenCommunity := make(map[int64]struct{})
+	for _, postID := range postIDs {
+		post, ok := posts[postID]
+		if !ok || post.Status == models.PostStatusDeleted {
 			continue
 		}
-		postList = append(postList, postDetial)
+		validPosts = append(validPosts, post)
+		validIDs = append(validIDs, postID)
+		authorIDs = append(authorIDs, post.AuthorID)
+		if _, ok := seenCommunity[post.CommunityID]; !ok {
+			seenCommunity[post.CommunityID] = struct{}{}
+			communityIDs = append(communityIDs, post.CommunityID)
+		}
 	}
-	postsAndToken = &models.PostsAndToken{
-		Token:    nextPageToken,
-		PostList: postList,
+	// 获取社区信息
+	communities := getCommunityDetails(ctx, communityIDs)
+	// 获取作者用户名
+	authorNames, err := mysql.GetUserNames(ctx, authorIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetUserNames failed", zap.Error(err))
 	}
-	return postsAndToken, nil
+	// 获取投票数
+	voteNums, err := redis.GetVoteNums(ctx, validIDs)
+	if err != nil {
+		zap.L().Error("redis.GetVoteNums failed", zap.Error(err))
+	}
+	// 获取评论数
+	commentNums := getCommentNums(ctx, validIDs)
+	// 合并数据
+	postList = make([]*models.ApiPostDetail, 0, len(validPosts))
+	for _, post := range validPosts {
+		voteNum, ok := voteNums[post.PostID]
+		if !ok {
+			voteNum = &models.VoteNum{PostID: post.PostID}
+		}
+		postList = append(postList, &models.ApiPostDetail{
+			AuthorName:      authorNames[post.AuthorID],
+			VoteNum:         voteNum.UpNum - voteNum.DownNum,
+			UpNum:           voteNum.UpNum,
+			DownNum:         voteNum.DownNum,
+			CommentNum:      commentNums[post.PostID],
+			Post:            post,
+			CommunityDetail: communities[post.CommunityID],
+		})
+	}
+	return postList, nil
 }
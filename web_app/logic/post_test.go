@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/settings"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// setupRedis 启动内存redis并初始化redis客户端
+func setupRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := redis.Init(&settings.RedisConfig{Host: mr.Host(), Port: port}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(redis.Close)
+	return mr
+}
+
+// TestGetPostListPageSize 每页数量未配置时使用默认最大值,超过最大值时返回ErrorInvalidPageSize
+func TestGetPostListPageSize(t *testing.T) {
+	setupRedis(t)
+	ctx := context.Background()
+
+	old := settings.Conf.MaxPageSize
+	t.Cleanup(func() { settings.Conf.MaxPageSize = old })
+
+	settings.Conf.MaxPageSize = 0
+	if got := maxPageSize(); got != DefaultMaxPageSize {
+		t.Errorf("maxPageSize() = %d, want %d", got, DefaultMaxPageSize)
+	}
+
+	settings.Conf.MaxPageSize = 20
+	if got := maxPageSize(); got != 20 {
+		t.Errorf("maxPageSize() = %d, want 20", got)
+	}
+	p := &models.ParamGetPostsInOrder{CommunityID: 1, Order: models.OrderTime, PageSize: 21}
+	if _, err := GetPostList(ctx, p); err != ErrorInvalidPageSize {
+		t.Errorf("GetPostList(page_size=21) err = %v, want %v", err, ErrorInvalidPageSize)
+	}
+}
@@ -0,0 +1,312 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"time"
+	"web_app/dao/redis"
+	"web_app/kafka"
+	"web_app/models"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	rankingRefreshBatchSize             = 100         // 每次从redis读取的待计算帖子数量
+	defaultRankingRefreshInterval       = time.Minute // 默认重新计算帖子排名的间隔
+	hotEpoch                      int64 = 1134028003  // Reddit hot算法的起始时间戳
+)
+
+// PostStat 计算帖子排名所需的统计数据
+type PostStat struct {
+	PostID     int64
+	CreateTime int64 // 发帖时间戳
+	UpNum      int64
+	DownNum    int64
+	CommentNum int64
+}
+
+// RankingStrategy 帖子排序策略,每个策略在 lightning:post:rank:<name> 中维护自己的排名
+type RankingStrategy interface {
+	// Name 策略名称,即 ParamGetPostsInOrder.Order 的取值
+	Name() string
+	// Score 计算帖子在该策略下的分数,分数越高排名越靠前
+	Score(stat *PostStat, now time.Time) float64
+	// Decay 分数是否随时间变化,随时间变化的策略需要定期重新计算
+	Decay() bool
+}
+
+// scoreStrategy 发帖时间加每票432分、每条评论216分,分数在投票和评论时增量更新
+type scoreStrategy struct{}
+
+func (scoreStrategy) Name() string { return models.OrderScore }
+
+func (scoreStrategy) Decay() bool { return false }
+
+func (scoreStrategy) Score(stat *PostStat, now time.Time) float64 {
+	return float64(stat.CreateTime + (stat.UpNum-stat.DownNum)*scorePerVote + stat.CommentNum*scorePerComment)
+}
+
+// gravityStrategy Hacker News 算法,净票数除以帖子时长的1.8次方,分数随时间衰减
+type gravityStrategy struct{}
+
+func (gravityStrategy) Name() string { return "gravity" }
+
+func (gravityStrategy) Decay() bool { return true }
+
+func (gravityStrategy) Score(stat *PostStat, now time.Time) float64 {
+	ageHours := math.Max(float64(now.Unix()-stat.CreateTime)/3600, 0)
+	return float64(stat.UpNum-stat.DownNum) / math.Pow(ageHours+2, 1.8)
+}
+
+// hotStrategy Reddit hot 算法,净票数取log10后加上发帖时间,每12.5小时相当于10倍的票数
+type hotStrategy struct{}
+
+func (hotStrategy) Name() string { return "hot" }
+
+// Decay 发帖时间已计入分数,分数只随投票变化
+func (hotStrategy) Decay() bool { return false }
+
+func (hotStrategy) Score(stat *PostStat, now time.Time) float64 {
+	s := stat.UpNum - stat.DownNum
+	order := math.Log10(math.Max(math.Abs(float64(s)), 1))
+	var sign float64
+	if s > 0 {
+		sign = 1
+	} else if s < 0 {
+		sign = -1
+	}
+	seconds := float64(stat.CreateTime - hotEpoch)
+	return sign*order + seconds/45000
+}
+
+// bestStrategy 赞成率的 Wilson score 置信区间下界(95%置信度),不考虑发帖时间
+type bestStrategy struct{}
+
+func (bestStrategy) Name() string { return "best" }
+
+func (bestStrategy) Decay() bool { return false }
+
+func (bestStrategy) Score(stat *PostStat, now time.Time) float64 {
+	n := float64(stat.UpNum + stat.DownNum)
+	if n == 0 {
+		return 0
+	}
+	const z = 1.96
+	phat := float64(stat.UpNum) / n
+	return (phat + z*z/(2*n) - z*math.Sqrt((phat*(1-phat)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
+
+// rankingStrategies 所有可用的排序策略
+var rankingStrategies = map[string]RankingStrategy{
+	models.OrderScore: scoreStrategy{},
+	"gravity":         gravityStrategy{},
+	"hot":             hotStrategy{},
+	"best":            bestStrategy{},
+}
+
+// enabledRankings 启用的排序策略
+var enabledRankings = map[string]RankingStrategy{
+	models.OrderScore: scoreStrategy{},
+}
+
+// InitRanking 根据配置启用排序策略,score策略始终启用
+func InitRanking(cfg *settings.RankingConfig) (err error) {
+	enabled := map[string]RankingStrategy{
+		models.OrderScore: scoreStrategy{},
+	}
+	if cfg != nil {
+		for _, name := range cfg.Strategies {
+			strategy, ok := rankingStrategies[name]
+			if !ok {
+				return ErrorInvalidOrder
+			}
+			enabled[name] = strategy
+		}
+	}
+	names := make([]string, 0, len(enabled))
+	for name := range enabled {
+		names = append(names, name)
+	}
+	enabledRankings = enabled
+	redis.SetRankNames(names)
+	// 新帖子和恢复的帖子写入redis后立即加入各排序策略
+	kafka.SetPostRankRefresher(addPostRank)
+	kafka.SetPostScoreRestorer(restorePostScore)
+	return nil
+}
+
+// checkOrder 校验排序方式,为空时使用默认的score排序
+func checkOrder(order string) (string, error) {
+	if order == "" {
+		return models.OrderScore, nil
+	}
+	if order == models.OrderTime {
+		return order, nil
+	}
+	if _, ok := enabledRankings[order]; !ok {
+		return "", ErrorInvalidOrder
+	}
+	return order, nil
+}
+
+// RunRankingRefresher 启动时计算所有帖子在各排序策略下的分数,之后定期重新计算分数随时间变化的策略,ctx取消时退出
+// 新帖子写入redis时加入各策略的排名,投票和评论时更新分数
+func RunRankingRefresher(ctx context.Context) {
+	interval := defaultRankingRefreshInterval
+	if cfg := settings.Conf.RankingConfig; cfg != nil && cfg.RefreshInterval > 0 {
+		interval = cfg.RefreshInterval
+	}
+	if err := BackfillPostRanks(ctx); err != nil && ctx.Err() == nil {
+		zap.L().Error("BackfillPostRanks failed", zap.Error(err))
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("ranking refresher stopped")
+			return
+		case <-ticker.C:
+		}
+		if err := RefreshPostRanks(ctx); err != nil && ctx.Err() == nil {
+			zap.L().Error("RefreshPostRanks failed", zap.Error(err))
+		}
+	}
+}
+
+// BackfillPostRanks 计算所有帖子在启用的各排序策略下的分数,补齐 refresh_window 之外的帖子和新启用的策略
+func BackfillPostRanks(ctx context.Context) (err error) {
+	return refreshPostRanks(ctx, 0, enabledRankings, true)
+}
+
+// RefreshPostRanks 重新计算 refresh_window 内发布的帖子在分数随时间变化的排序策略下的分数
+func RefreshPostRanks(ctx context.Context) (err error) {
+	var min int64
+	if cfg := settings.Conf.RankingConfig; cfg != nil && cfg.RefreshWindow > 0 {
+		min = time.Now().Add(-cfg.RefreshWindow).Unix()
+	}
+	strategies := make(map[string]RankingStrategy, len(enabledRankings))
+	for name, strategy := range enabledRankings {
+		if strategy.Decay() {
+			strategies[name] = strategy
+		}
+	}
+	return refreshPostRanks(ctx, min, strategies, false)
+}
+
+// refreshPostRanks 分批计算创建时间不早于min的帖子在strategies下的分数,add为false时只更新已在排序中的帖子
+func refreshPostRanks(ctx context.Context, min int64, strategies map[string]RankingStrategy, add bool) (err error) {
+	if len(strategies) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var offset int64
+	for {
+		createTimes, err := redis.GetPostCreateTimes(ctx, min, now.Unix(), offset, rankingRefreshBatchSize)
+		if err != nil {
+			zap.L().Error("redis.GetPostCreateTimes failed", zap.Error(err))
+			return err
+		}
+		if err = updatePostRanks(ctx, createTimes, now, strategies, add); err != nil {
+			return err
+		}
+		if len(createTimes) < rankingRefreshBatchSize {
+			return nil
+		}
+		offset += int64(len(createTimes))
+	}
+}
+
+// refreshPostRank 帖子的投票或评论变化后重新计算其在各排序策略下的分数
+func refreshPostRank(ctx context.Context, postID int64) {
+	rankPost(ctx, postID, false)
+}
+
+// addPostRank 新创建或恢复的帖子写入redis后加入各排序策略
+func addPostRank(ctx context.Context, postID int64) {
+	rankPost(ctx, postID, true)
+}
+
+// rankPost 计算帖子在各排序策略下的分数,add为false时只更新已在排序中的帖子
+func rankPost(ctx context.Context, postID int64, add bool) {
+	createTime, err := redis.GetPostCreateTime(ctx, postID)
+	if err != nil {
+		zap.L().Warn("redis.GetPostCreateTime failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return
+	}
+	if err = updatePostRanks(ctx, map[int64]int64{postID: createTime}, time.Now(), enabledRankings, add); err != nil {
+		zap.L().Error("updatePostRanks failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+	}
+}
+
+// restorePostScore 被删除的帖子恢复时按投票数和评论数重新计算score策略的分数
+// 删除帖子时投票数和评论数仍保留,恢复后分数与删除前一致
+func restorePostScore(ctx context.Context, postID, createTime int64) (err error) {
+	commentNum, err := getCommentNum(ctx, postID)
+	if err != nil {
+		zap.L().Error("getCommentNum failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if err = redis.RestorePostScore(ctx, postID, createTime, commentNum, scorePerVote, scorePerComment); err != nil {
+		zap.L().Error("redis.RestorePostScore failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// updatePostRanks 根据帖子的投票数和评论数计算strategies下的分数并写入redis
+// add为true时将帖子加入排序,否则只更新已在排序中的帖子,避免把并发删除的帖子重新加入
+func updatePostRanks(ctx context.Context, createTimes map[int64]int64, now time.Time, strategies map[string]RankingStrategy, add bool) (err error) {
+	// score策略的分数由投票脚本和评论增量维护,不重新计算
+	ranks := make(map[string]map[int64]float64, len(strategies))
+	for name := range strategies {
+		if name != models.OrderScore {
+			ranks[name] = make(map[int64]float64, len(createTimes))
+		}
+	}
+	if len(createTimes) == 0 || len(ranks) == 0 {
+		return nil
+	}
+	postIDs := make([]int64, 0, len(createTimes))
+	for postID := range createTimes {
+		postIDs = append(postIDs, postID)
+	}
+	voteNums, err := redis.GetVoteNums(ctx, postIDs)
+	if err != nil {
+		zap.L().Error("redis.GetVoteNums failed", zap.Error(err))
+		return err
+	}
+	commentNums := getCommentNums(ctx, postIDs)
+	for _, postID := range postIDs {
+		stat := &PostStat{
+			PostID:     postID,
+			CreateTime: createTimes[postID],
+			CommentNum: commentNums[postID],
+		}
+		if voteNum, ok := voteNums[postID]; ok {
+			stat.UpNum = voteNum.UpNum
+			stat.DownNum = voteNum.DownNum
+		}
+		for name, scores := range ranks {
+			scores[postID] = strategies[name].Score(stat, now)
+		}
+	}
+	if add {
+		return redis.AddPostRanks(ctx, ranks)
+	}
+	return redis.SetPostRanks(ctx, ranks)
+}
@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"web_app/dao/mysql"
+	"web_app/models"
+
+	"go.uber.org/zap"
+)
+
+// SetUserRole 设置用户角色,角色变更在用户刷新accessToken后生效
+func SetUserRole(ctx context.Context, userID int64, role int8) (err error) {
+	if _, err = getUserRole(ctx, userID); err != nil {
+		return err
+	}
+	// 取消管理员时,仍担任版主的用户降为版主
+	if role == models.RoleUser {
+		ids, err := mysql.GetModeratedCommunityIDs(ctx, userID)
+		if err != nil {
+			zap.L().Error("mysql.GetModeratedCommunityIDs failed", zap.Int64("user_id", userID), zap.Error(err))
+			return err
+		}
+		if len(ids) > 0 {
+			role = models.RoleModerator
+		}
+	}
+	if err = mysql.UpdateUserRole(ctx, userID, role); err != nil {
+		zap.L().Error("mysql.UpdateUserRole failed",
+			zap.Int64("user_id", userID),
+			zap.Int8("role", role),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// AddModerator 指派用户为社区版主
+func AddModerator(ctx context.Context, communityID, userID int64) (err error) {
+	// 查看社区和用户是否存在
+	if _, err = getCommunityInMysql(communityID); err != nil {
+		return err
+	}
+	role, err := getUserRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = mysql.CreateModerator(ctx, communityID, userID); err != nil {
+		zap.L().Error("mysql.CreateModerator failed",
+			zap.Int64("community_id", communityID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return err
+	}
+	// 普通用户升为版主,管理员角色不变
+	if role == models.RoleUser {
+		if err = mysql.UpdateUserRole(ctx, userID, models.RoleModerator); err != nil {
+			zap.L().Error("mysql.UpdateUserRole failed", zap.Int64("user_id", userID), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveModerator 取消用户的社区版主身份
+func RemoveModerator(ctx context.Context, communityID, userID int64) (err error) {
+	role, err := getUserRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = mysql.DeleteModerator(ctx, communityID, userID); err != nil {
+		zap.L().Error("mysql.DeleteModerator failed",
+			zap.Int64("community_id", communityID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if role != models.RoleModerator {
+		return nil
+	}
+	// 不再担任任何社区版主的用户降为普通用户
+	ids, err := mysql.GetModeratedCommunityIDs(ctx, userID)
+	if err != nil {
+		zap.L().Error("mysql.GetModeratedCommunityIDs failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	if len(ids) == 0 {
+		if err = mysql.UpdateUserRole(ctx, userID, models.RoleUser); err != nil {
+			zap.L().Error("mysql.UpdateUserRole failed", zap.Int64("user_id", userID), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// getUserRole 从数据库获得用户角色
+func getUserRole(ctx context.Context, userID int64) (role int8, err error) {
+	role, err = mysql.GetUserRole(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotFound) {
+			return 0, ErrorUserNotExist
+		}
+		zap.L().Error("mysql.GetUserRole failed", zap.Int64("user_id", userID), zap.Error(err))
+		return 0, err
+	}
+	return role, nil
+}
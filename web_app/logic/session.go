@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"web_app/dao/redis"
+	"web_app/models"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+)
+
+// GetSessions 获得用户所有未过期的登录会话,currentSessionID为当前请求使用的会话
+func GetSessions(ctx context.Context, userID int64, currentSessionID string) (sessions []*models.Session, err error) {
+	sessions, err = redis.GetSessions(ctx, userID)
+	if err != nil {
+		zap.L().Error("redis.GetSessions failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的一个登录会话,该会话的RefreshToken和AccessToken立即失效
+func RevokeSession(ctx context.Context, userID int64, sessionID string) (err error) {
+	if _, err = redis.GetSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, redis.ErrorSessionNotFound) {
+			return ErrorSessionNotExist
+		}
+		zap.L().Error("redis.GetSession failed",
+			zap.Int64("user_id", userID),
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if err = redis.RevokeSessions(ctx, userID, []string{sessionID}, settings.Conf.AccessTokenDuration); err != nil {
+		zap.L().Error("redis.RevokeSessions failed",
+			zap.Int64("user_id", userID),
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// RevokeAllSessions 撤销用户的所有登录会话
+func RevokeAllSessions(ctx context.Context, userID int64) (err error) {
+	if err = redis.RevokeSessions(ctx, userID, nil, settings.Conf.AccessTokenDuration); err != nil {
+		zap.L().Error("redis.RevokeSessions failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// IsSessionRevoked 判断AccessToken所属的会话是否已被撤销
+func IsSessionRevoked(ctx context.Context, sessionID string) (revoked bool, err error) {
+	revoked, err = redis.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		zap.L().Error("redis.IsSessionRevoked failed", zap.String("session_id", sessionID), zap.Error(err))
+		return false, err
+	}
+	return revoked, nil
+}
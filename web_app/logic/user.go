@@ -3,6 +3,9 @@ package logic
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 	"web_app/dao/mysql"
 	"web_app/dao/redis"
 	"web_app/models"
@@ -28,8 +31,11 @@ func encryptPassword(oPassword string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-// 注册业务处理
+// 注册业务处理,填写了邮箱时发送验证邮件
 func SignUp(ctx context.Context, p *models.ParamSignUp) (err error) {
+	if p.Email == "" && settings.Conf.AccountConfig.RequireEmail {
+		return ErrorEmailRequired
+	}
 	// 判断用户存不存在
 	if err := mysql.UsernameExists(ctx, p.Username); err != nil {
 		if errors.Is(err, mysql.ErrorUsernameExist) {
@@ -37,6 +43,15 @@ func SignUp(ctx context.Context, p *models.ParamSignUp) (err error) {
 		}
 		return err
 	}
+	// 判断邮箱是否被使用
+	if p.Email != "" {
+		if err := mysql.EmailExists(ctx, 0, p.Email); err != nil {
+			if errors.Is(err, mysql.ErrorEmailExist) {
+				return ErrorEmailExist
+			}
+			return err
+		}
+	}
 	// 加密密码
 	hashedPassword, err := encryptPassword(p.Password)
 	if err != nil {
@@ -47,14 +62,30 @@ func SignUp(ctx context.Context, p *models.ParamSignUp) (err error) {
 		UserID:   snowflake.GenID(),
 		Username: p.Username,
 		Password: hashedPassword,
+		Email:    p.Email,
 	}
 
-	// 保存进数据库
-	return mysql.CreateUser(user)
+	// 保存进数据库,并发注册时以数据库的唯一索引为准
+	if err = mysql.CreateUser(user); err != nil {
+		if errors.Is(err, mysql.ErrorUsernameExist) {
+			return ErrorUserExist
+		}
+		if errors.Is(err, mysql.ErrorEmailExist) {
+			return ErrorEmailExist
+		}
+		return err
+	}
+	// 账号以未验证状态创建,验证邮件发送失败时用户可以重新发送
+	if user.Email != "" {
+		if err := sendVerifyEmail(ctx, user.UserID, user.Username, user.Email); err != nil {
+			zap.L().Warn("send verify email failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+		}
+	}
+	return nil
 }
 
-// 登录业务处理
-func Login(ctx context.Context, p *models.ParamLogin) (accessToken, refreshToken string, err error) {
+// 登录业务处理,每次登录创建一个新的会话,不影响用户在其他设备上的会话
+func Login(ctx context.Context, p *models.ParamLogin, userAgent, ip string) (accessToken, refreshToken string, err error) {
 	// 创建用户信息结构体
 	user := new(models.User)
 	// 通过用户名从Mysql中获取用户信息
@@ -69,34 +100,208 @@ func Login(ctx context.Context, p *models.ParamLogin) (accessToken, refreshToken
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(p.Password)); err != nil {
 		return "", "", ErrorInvalidPassword
 	}
-	// 获取jwtAccessToken和RefreshToken
-	accessToken, err = genToken(user.UserID, user.Password, AccessTokenType)
+	// 获取token中的用户信息
+	info, err := newUserInfo(ctx, user)
+	if err != nil {
+		zap.L().Error("failed to get user info", zap.Int64("userID", user.UserID), zap.Error(err))
+		return "", "", err
+	}
+	// 获取jwtAccessToken和RefreshToken,会话ID写入token的jti
+	sessionID := strconv.FormatInt(snowflake.GenID(), 10)
+	accessToken, err = genToken(info, sessionID, AccessTokenType)
 	if err != nil {
 		zap.L().Error("failed to generate access token", zap.Error(err))
 		return "", "", err
 	}
-	refreshToken, err = genToken(user.UserID, user.Password, RefreshTokenType)
+	refreshToken, err = genToken(info, sessionID, RefreshTokenType)
 	if err != nil {
 		zap.L().Error("failed to generate refresh token", zap.Error(err))
 		return "", "", err
 	}
-	// 将refreshTokn存入redis
-	if err = redis.CreateRereshToken(ctx, user.UserID, refreshToken, settings.Conf.RefreshTokenDuration); err != nil {
-		zap.L().Error("failed to store refresh token in redis", zap.Error(err))
+	// 将会话和refreshTokn存入redis
+	now := time.Now()
+	session := &models.Session{
+		SessionID:      sessionID,
+		UserAgent:      userAgent,
+		IP:             ip,
+		CreateTime:     now,
+		LastActiveTime: now,
+		RefreshToken:   refreshToken,
+	}
+	if err = redis.CreateSession(ctx, user.UserID, session, settings.Conf.RefreshTokenDuration); err != nil {
+		zap.L().Error("failed to store session in redis", zap.Int64("userID", user.UserID), zap.Error(err))
 		return "", "", err
 	}
 	return accessToken, refreshToken, nil
 }
 
-// genToken 根据tokenType生成accessToken或refreshToken
-func genToken(userID int64, username string, tokenType string) (token string, err error) {
+// newUserInfo 获得存入token的用户信息,版主需要查询其管理的社区
+func newUserInfo(ctx context.Context, user *models.User) (info jwt.UserInfo, err error) {
+	info = jwt.UserInfo{
+		UserID:   user.UserID,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+	if user.Role == models.RoleModerator {
+		info.CommunityIDs, err = mysql.GetModeratedCommunityIDs(ctx, user.UserID)
+		if err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
+
+// genToken 根据tokenType生成会话的accessToken或refreshToken
+func genToken(info jwt.UserInfo, sessionID, tokenType string) (token string, err error) {
 	if tokenType == AccessTokenType {
-		token, err = jwt.GenAccessToken(userID, username, settings.Conf.AccessTokenDuration)
+		token, err = jwt.GenAccessToken(info, sessionID, settings.Conf.AccessTokenDuration)
 		return
 	}
 	if tokenType == RefreshTokenType {
-		token, err = jwt.GenRefreshToken(userID, username, settings.Conf.RefreshTokenDuration)
+		token, err = jwt.GenRefreshToken(info, sessionID, 0, settings.Conf.RefreshTokenDuration)
 		return
 	}
 	return "", ErrorWorngTokenType
 }
+
+// getUserName 从数据库获得用户名
+func getUserName(ctx context.Context, userID int64) (username string, err error) {
+	username, err = mysql.GetUserName(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotFound) {
+			return "", ErrorUserNotExist
+		}
+		zap.L().Error("mysql.GetUserName failed", zap.Int64("user_id", userID), zap.Error(err))
+		return "", err
+	}
+	return username, nil
+}
+
+// GetUserProfile 获得用户主页信息
+func GetUserProfile(ctx context.Context, userID int64) (profile *models.ApiUserProfile, err error) {
+	userProfile, err := getUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 帖子数量从作者的帖子ZSet中获得
+	if err = loadAuthorPosts(ctx, userID); err != nil {
+		return nil, err
+	}
+	postNum, err := redis.GetUserPostNum(ctx, userID)
+	if err != nil {
+		zap.L().Error("redis.GetUserPostNum failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	karma, err := redis.GetUserKarma(ctx, userID)
+	if err != nil {
+		zap.L().Error("redis.GetUserKarma failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	followerNum, err := mysql.GetFollowerNum(ctx, userID)
+	if err != nil {
+		zap.L().Error("mysql.GetFollowerNum failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	followingNum, err := mysql.GetFollowingNum(ctx, userID)
+	if err != nil {
+		zap.L().Error("mysql.GetFollowingNum failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	profile = &models.ApiUserProfile{
+		UserID:       userID,
+		Username:     userProfile.Username,
+		Gender:       userProfile.Gender,
+		Bio:          userProfile.Bio,
+		AvatarURL:    userProfile.AvatarURL,
+		PostNum:      postNum,
+		Karma:        karma,
+		FollowerNum:  followerNum,
+		FollowingNum: followingNum,
+	}
+	return profile, nil
+}
+
+// GetMyProfile 获得当前用户的个人资料
+func GetMyProfile(ctx context.Context, userID int64) (profile *models.UserProfile, err error) {
+	return getUserProfile(ctx, userID)
+}
+
+// UpdateMyProfile 编辑当前用户的个人资料,邮箱变更后需要重新验证
+func UpdateMyProfile(ctx context.Context, userID int64, p *models.ParamUpdateProfile) (err error) {
+	profile, err := getUserProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	emailChanged := p.Email != "" && p.Email != profile.Email
+	if emailChanged {
+		if err = mysql.EmailExists(ctx, userID, p.Email); err != nil {
+			if errors.Is(err, mysql.ErrorEmailExist) {
+				return ErrorEmailExist
+			}
+			zap.L().Error("mysql.EmailExists failed", zap.Int64("user_id", userID), zap.Error(err))
+			return err
+		}
+	}
+	if err = mysql.UpdateUserProfile(ctx, userID, p); err != nil {
+		if errors.Is(err, mysql.ErrorEmailExist) {
+			return ErrorEmailExist
+		}
+		zap.L().Error("mysql.UpdateUserProfile failed", zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+	if emailChanged {
+		if err := sendVerifyEmail(ctx, userID, profile.Username, p.Email); err != nil {
+			zap.L().Warn("send verify email failed", zap.Int64("user_id", userID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// GetUserPostList 获得用户发布的帖子列表,按发帖时间倒序
+func GetUserPostList(ctx context.Context, userID int64, p *models.ParamGetUserPosts) (postsAndToken *models.PostsAndToken, err error) {
+	// 查看用户是否存在
+	if _, err = getUserName(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err = loadAuthorPosts(ctx, userID); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("author_id=%d", userID)
+	return getPostPage(ctx, redis.GetKeyUserPostsZSet(userID), query, p.Token, p.PageSize)
+}
+
+// getUserProfile 从数据库获得用户的个人资料
+func getUserProfile(ctx context.Context, userID int64) (profile *models.UserProfile, err error) {
+	profile, err = mysql.GetUserProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotFound) {
+			return nil, ErrorUserNotExist
+		}
+		zap.L().Error("mysql.GetUserProfile failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return profile, nil
+}
+
+// loadAuthorPosts 作者的帖子未加载时从数据库加载,没有帖子的作者也会记录已加载
+// 新帖子始终由binlog消息写入ZSet,与加载并发时也不会丢失;加载期间被删除的帖子不在时间ZSet中,不会被写回
+func loadAuthorPosts(ctx context.Context, authorID int64) (err error) {
+	exist, err := redis.UserPostsExist(ctx, authorID)
+	if err != nil {
+		zap.L().Error("redis.UserPostsExist failed", zap.Int64("author_id", authorID), zap.Error(err))
+		return err
+	}
+	if exist {
+		return nil
+	}
+	posts, err := mysql.GetAuthorPosts(ctx, authorID)
+	if err != nil {
+		zap.L().Error("mysql.GetAuthorPosts failed", zap.Int64("author_id", authorID), zap.Error(err))
+		return err
+	}
+	if err = redis.SetUserPosts(ctx, authorID, posts); err != nil {
+		zap.L().Error("redis.SetUserPosts failed", zap.Int64("author_id", authorID), zap.Error(err))
+		return err
+	}
+	return nil
+}
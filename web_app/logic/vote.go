@@ -3,38 +3,49 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+	"web_app/dao/mysql"
 	"web_app/dao/redis"
 	"web_app/kafka"
 	"web_app/models"
+	"web_app/settings"
 
 	"go.uber.org/zap"
 )
 
 const (
 	scorePerVote = 432 //每票价值432分 86400/200  --> 200张赞成票可以给你的帖子续一天
+
+	voteArchiveInterval  = 10 * time.Minute // 投票归档任务执行间隔
+	voteArchiveDelay     = time.Minute      // 归档比投票期限多等待的时间,避免与期限边界上的投票竞争
+	voteArchiveBatchSize = 100              // 每次从redis读取的待归档帖子数量
 )
 
 // VoteForPost 帖子投票业务
 func VoteForPost(ctx context.Context, userID int64, p *models.ParamVoteForPost) (err error) {
+	// 配置不允许时,邮箱未验证的用户不能投票
+	if err = checkEmailVerified(ctx, userID, settings.Conf.AccountConfig.UnverifiedCanVote); err != nil {
+		return err
+	}
 	votePost := &models.VotePost{
 		PostID:   p.PostID,
 		UserID:   userID,
 		VoteType: p.VoteType,
 	}
-	// 将投票数据序列化为json格式
-	data, err := json.Marshal(votePost)
-	if err != nil {
-		zap.L().Error("json.Marshal failed",
-			zap.Int64("user_id", votePost.UserID),
-			zap.Int64("post_id", votePost.PostID),
-			zap.Error(err),
-		)
-		return err
+	// 已删除、不存在和超出投票期限的帖子不能投票
+	deadline := voteDeadline()
+	// 原子地检查投票期限和旧投票类型,写入新投票类型、赞成票和反对票数量并更新帖子分数
+	oVoteType, err := redis.VoteForPost(ctx, scorePerVote, deadline, votePost)
+	if errors.Is(err, redis.ErrorVoteTimeExpired) {
+		return ErrorVoteTimeExpired
+	}
+	if errors.Is(err, redis.ErrorPostNotExist) {
+		return ErrorPostNotExist
 	}
-	// 获得当前帖子下的当前用户投票类型
-	oVoteType, err := redis.GetVoteType(ctx, votePost)
 	if err != nil {
-		zap.L().Error("redis.GetVoteType failed",
+		zap.L().Error("redis.VoteForPost failed",
 			zap.Int64("user_id", votePost.UserID),
 			zap.Int64("post_id", votePost.PostID),
 			zap.Error(err),
@@ -45,13 +56,14 @@ func VoteForPost(ctx context.Context, userID int64, p *models.ParamVoteForPost)
 	if oVoteType == votePost.VoteType {
 		return ErrorVoteRepeated
 	}
+	// 更新帖子在其他排序策略下的分数
+	refreshPostRank(ctx, votePost.PostID)
 
-	// 计算新旧投票类型的差值
-	diff := votePost.VoteType - oVoteType
-	changeScore := int(diff) * scorePerVote
-	// 将投票数据存入redis
-	if err = redis.VoteForPost(ctx, changeScore, votePost); err != nil {
-		zap.L().Error("redis.VoteForPost failed",
+	// 将投票数据序列化为json格式,旧投票类型用于计算作者karma的变化
+	votePost.OldVoteType = oVoteType
+	data, err := json.Marshal(votePost)
+	if err != nil {
+		zap.L().Error("json.Marshal failed",
 			zap.Int64("user_id", votePost.UserID),
 			zap.Int64("post_id", votePost.PostID),
 			zap.Error(err),
@@ -70,3 +82,123 @@ func VoteForPost(ctx context.Context, userID int64, p *models.ParamVoteForPost)
 	}
 	return nil
 }
+
+// voteDeadline 获得允许投票和改变帖子分数的最早发帖时间,未配置投票期限时返回0
+func voteDeadline() int64 {
+	if window := settings.Conf.VoteWindow; window > 0 {
+		return time.Now().Add(-window).Unix()
+	}
+	return 0
+}
+
+// RebuildVoteNums 按redis中的用户投票重新统计所有帖子的赞成票和反对票数量
+// 用户投票先写入redis再经kafka异步持久化,以redis为准不会丢失还没有写入数据库的投票
+// 已归档帖子的用户投票已从redis删除,从归档表恢复其最终计数
+func RebuildVoteNums(ctx context.Context) (err error) {
+	archives, err := mysql.GetArchivedVoteNums(ctx)
+	if err != nil {
+		zap.L().Error("mysql.GetArchivedVoteNums failed", zap.Error(err))
+		return err
+	}
+	if err = redis.SetVoteNums(ctx, archives); err != nil {
+		zap.L().Error("redis.SetVoteNums failed", zap.Error(err))
+		return err
+	}
+	if err = redis.RecountVoteNums(ctx, voteDeadline()); err != nil {
+		zap.L().Error("redis.RecountVoteNums failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RunVoteArchiver 定期归档超出投票期限的帖子,ctx取消时退出,未配置投票期限时不启动
+func RunVoteArchiver(ctx context.Context) {
+	if settings.Conf.VoteWindow <= 0 {
+		return
+	}
+	ticker := time.NewTicker(voteArchiveInterval)
+	defer ticker.Stop()
+	for {
+		if err := ArchiveExpiredVotes(ctx); err != nil && ctx.Err() == nil {
+			zap.L().Error("ArchiveExpiredVotes failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			zap.L().Info("vote archiver stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveExpiredVotes 将上次归档之后超出投票期限的帖子的最终分数和投票归档到mysql,并移除redis中的用户投票
+// 帖子仍保留在排序ZSet中,分数不再变化
+func ArchiveExpiredVotes(ctx context.Context) (err error) {
+	cursor, err := redis.GetVoteArchiveCursor(ctx)
+	if err != nil {
+		zap.L().Error("redis.GetVoteArchiveCursor failed", zap.Error(err))
+		return err
+	}
+	deadline := time.Now().Add(-settings.Conf.VoteWindow - voteArchiveDelay).Unix()
+	if deadline <= cursor {
+		return nil
+	}
+	var offset int64
+	for {
+		postIDStrs, err := redis.GetPostIDsByTime(ctx, cursor, deadline, offset, voteArchiveBatchSize)
+		if err != nil {
+			zap.L().Error("redis.GetPostIDsByTime failed",
+				zap.Int64("cursor", cursor),
+				zap.Int64("deadline", deadline),
+				zap.Error(err),
+			)
+			return err
+		}
+		for _, postIDStr := range postIDStrs {
+			postID, err := strconv.ParseInt(postIDStr, 10, 64)
+			if err != nil {
+				zap.L().Error("strconv.ParseInt failed",
+					zap.String("postIDStr", postIDStr),
+					zap.Error(err),
+				)
+				continue
+			}
+			// 归档失败时不推进进度,下次重新归档
+			if err = archivePostVotes(ctx, postID); err != nil {
+				return err
+			}
+		}
+		if len(postIDStrs) < voteArchiveBatchSize {
+			break
+		}
+		offset += int64(len(postIDStrs))
+	}
+	return redis.SetVoteArchiveCursor(ctx, deadline)
+}
+
+// archivePostVotes 归档单个帖子的投票结果
+func archivePostVotes(ctx context.Context, postID int64) (err error) {
+	archive, votes, err := redis.GetVoteArchive(ctx, postID)
+	if err != nil {
+		zap.L().Error("redis.GetVoteArchive failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if err = mysql.ArchivePostVotes(ctx, archive, votes); err != nil {
+		zap.L().Error("mysql.ArchivePostVotes failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if err = redis.DeleteVotePostHash(ctx, postID); err != nil {
+		zap.L().Error("redis.DeleteVotePostHash failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
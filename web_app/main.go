@@ -13,10 +13,13 @@ import (
 	"web_app/dao/mysql"
 	"web_app/dao/redis"
 	"web_app/kafka"
+	"web_app/logic"
 
 	_ "web_app/docs" // 导入生成的 Swagger 文档
 	"web_app/logger"
 	"web_app/pkg/bloom"
+	"web_app/pkg/jwt"
+	"web_app/pkg/mailer"
 	"web_app/pkg/snowflake"
 	"web_app/routes"
 	"web_app/settings"
@@ -64,10 +67,46 @@ func main() {
 		zap.L().Error("bloom.InitBloomFilter() failed", zap.Error(err))
 		return
 	}
+	// 8.加载JWT签名密钥
+	if err := jwt.Init(settings.Conf.JWTConfig); err != nil {
+		zap.L().Error("jwt.Init() failed", zap.Error(err))
+		return
+	}
+	// 9.加载分页token签名密钥
+	if err := logic.InitPageToken(settings.Conf.PageTokenSecret); err != nil {
+		zap.L().Error("logic.InitPageToken failed", zap.Error(err))
+		return
+	}
+	// 10.初始化邮件发送
+	if err := mailer.Init(settings.Conf.MailerConfig); err != nil {
+		zap.L().Error("mailer.Init() failed", zap.Error(err))
+		return
+	}
+	// 11.初始化帖子排序策略
+	if err := logic.InitRanking(settings.Conf.RankingConfig); err != nil {
+		zap.L().Error("logic.InitRanking failed", zap.Error(err))
+		return
+	}
 	// 背景context
 	ctx, cancel := context.WithCancel(context.Background())
-	// 8.初始化kafka.Reader
+	// 12.从数据库加载已归档社区,需在kafka消费归档状态变化之前完成
+	if err := logic.InitArchivedCommunities(ctx); err != nil {
+		zap.L().Error("logic.InitArchivedCommunities failed", zap.Error(err))
+		cancel()
+		return
+	}
+	// 13.从数据库加载作者karma排行
+	if err := logic.InitUserKarmas(ctx); err != nil {
+		zap.L().Error("logic.InitUserKarmas failed", zap.Error(err))
+		cancel()
+		return
+	}
+	// 14.初始化kafka.Reader
 	kafka.Init(ctx, settings.Conf.KafkaConfig)
+	// 15.启动投票归档任务
+	go logic.RunVoteArchiver(ctx)
+	// 16.启动排名计算任务
+	go logic.RunRankingRefresher(ctx)
 	// 注册路由
 	r := routes.Setup(settings.Conf.Mode, settings.Conf.RatelimitConfig)
 	// 启动服务(优雅关机)
@@ -1,78 +1,75 @@
 package middlewares
 
 import (
-	"context"
-	"errors"
 	"strings"
 	"web_app/controller"
 	"web_app/logic"
 	"web_app/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
-// JWTMiddleware Token认证中间件
+// JWTMiddleware Token认证中间件,只校验accessToken
+// accessToken缺失或无效时返回CodeNeedLogin或CodeInvalidToken,由前端调用 /api/v2/token/refresh 刷新后重试
 func JWTMiddleware() func(c *gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context() //获取上下文
 
 		AuthorHead := c.Request.Header.Get("Authorization")
-		var mc *jwt.MyClaims
-		// 如果 Authorization 为空，检查 refreshToken
+		// Authorization 为空,需要登录或刷新accessToken
 		if AuthorHead == "" {
-			if handleRefreshToken(ctx, c) { // 如果 refreshToken 验证成功
-				c.Next() //执行后续handler
-				return   //结束Token认证
-			}
-			controller.ResponseError(c, controller.CodeNeedLogin)
-			c.Abort()
+			abortUnauthorized(c, controller.CodeNeedLogin)
 			return
 		}
 		// Authorization不为空,解析accessToken
 		parts := strings.SplitN(AuthorHead, " ", 2) //将Authorization按空格分割
 		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			controller.ResponseError(c, controller.CodeInvalidToken)
-			c.Abort()
+			abortUnauthorized(c, controller.CodeInvalidToken)
 			return
 		}
 
-		// 解析accessToken
-		mc, err := jwt.ParseToken(parts[1])
+		// 解析accessToken,refreshToken不能作为accessToken使用
+		mc, err := jwt.ParseAccessToken(parts[1])
+		if err != nil {
+			abortUnauthorized(c, controller.CodeInvalidToken)
+			return
+		}
+		// 检查accessToken所属的会话是否已被撤销
+		if mc.ID == "" {
+			abortUnauthorized(c, controller.CodeInvalidToken)
+			return
+		}
+		revoked, err := logic.IsSessionRevoked(ctx, mc.ID)
 		if err != nil {
-			// 如果accessToken不合规,检查 refreshToken
-			if handleRefreshToken(ctx, c) { // 如果 refreshToken 验证成功
-				c.Next() //执行后续handler
-				return   //结束Token认证
-			}
-			controller.ResponseError(c, controller.CodeInvalidToken)
+			controller.ResponseError(c, controller.CodeServerBusy)
 			c.Abort()
 			return
 		}
-		// 将用户id存入上下文
-		c.Set(controller.CtxUserIDKey, mc.UserID)
+		if revoked {
+			abortUnauthorized(c, controller.CodeInvalidToken)
+			return
+		}
+		// 将用户信息存入上下文
+		setUserInfo(c, mc)
 		c.Next()
 	}
 }
 
-func handleRefreshToken(ctx context.Context, c *gin.Context) bool {
-	// 从当前客户端Cookie获取refreshToken
-	refreshToken, err := c.Cookie(logic.RefreshCookieName)
-	if err != nil {
-		zap.L().Error("failed to get refresh_token in Cookie", zap.Error(err))
-		return false
-	}
-	// 验证 refreshToken 并生成新的 accessToken
-	accessToken, mc, err := logic.ValidateRefreshToken(ctx, refreshToken)
-	if err != nil {
-		if errors.Is(err, logic.ErrorRefreshTokenNotExist) || errors.Is(err, logic.ErrorInvalidRefeshToken) {
-			zap.L().Warn("RefreshToken expired")
-		}
-		return false
+// abortUnauthorized 返回认证失败响应,并在WWW-Authenticate头中说明原因
+func abortUnauthorized(c *gin.Context, code controller.ResCode) {
+	challenge := `Bearer realm="lightning"`
+	if code == controller.CodeInvalidToken {
+		challenge += `, error="invalid_token"`
 	}
-	// 返回成功响应，和新accessToken
-	controller.ResponseSuccess(c, accessToken)
-	// 将用户id存入上下文
+	c.Header("WWW-Authenticate", challenge)
+	controller.ResponseError(c, code)
+	c.Abort()
+}
+
+// setUserInfo 将token中的用户信息存入上下文
+func setUserInfo(c *gin.Context, mc *jwt.MyClaims) {
 	c.Set(controller.CtxUserIDKey, mc.UserID)
-	return true
+	c.Set(controller.CtxUserRoleKey, mc.Role)
+	c.Set(controller.CtxCommunityIDsKey, mc.CommunityIDs)
+	c.Set(controller.CtxSessionIDKey, mc.ID)
 }
@@ -0,0 +1,123 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"web_app/controller"
+	"web_app/dao/redis"
+	"web_app/logic"
+	"web_app/pkg/jwt"
+	"web_app/settings"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// setupAuth 启动内存redis并初始化JWT签名密钥
+func setupAuth(t *testing.T) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := redis.Init(&settings.RedisConfig{Host: mr.Host(), Port: port}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(redis.Close)
+	err = jwt.Init(&settings.JWTConfig{
+		SigningKeyID: "hs-test",
+		Keys: []*settings.JWTKeyConfig{
+			{KID: "hs-test", Algorithm: "HS256", Secret: strings.Repeat("s", 32)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveAuth 请求经过JWTMiddleware的接口,返回响应码、WWW-Authenticate头和处理函数中获得的用户ID
+func serveAuth(t *testing.T, setRequest func(req *http.Request)) (code controller.ResCode, challenge string, userID int64) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", JWTMiddleware(), func(c *gin.Context) {
+		userID, _ = controller.GetCurrentUserID(c)
+		controller.ResponseSuccess(c, nil)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	setRequest(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp controller.ResponseData
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Code, w.Header().Get("WWW-Authenticate"), userID
+}
+
+// TestJWTMiddleware 中间件只接受未撤销会话的accessToken,不再使用refreshToken刷新
+func TestJWTMiddleware(t *testing.T) {
+	setupAuth(t)
+	ctx := context.Background()
+
+	info := jwt.UserInfo{UserID: 1, Username: "alice"}
+	accessToken, err := jwt.GenAccessToken(info, "s1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshToken, err := jwt.GenRefreshToken(info, "s1", 0, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := func(token string) func(req *http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	const (
+		needLogin    = `Bearer realm="lightning"`
+		invalidToken = `Bearer realm="lightning", error="invalid_token"`
+	)
+	cases := []struct {
+		name          string
+		setRequest    func(req *http.Request)
+		wantCode      controller.ResCode
+		wantChallenge string
+		wantUserID    int64
+	}{
+		{"valid access token", bearer(accessToken), controller.CodeSuccess, "", 1},
+		{"missing authorization", func(req *http.Request) {}, controller.CodeNeedLogin, needLogin, 0},
+		{"not bearer", func(req *http.Request) {
+			req.Header.Set("Authorization", "Basic "+accessToken)
+		}, controller.CodeInvalidToken, invalidToken, 0},
+		{"refresh token as access token", bearer(refreshToken), controller.CodeInvalidToken, invalidToken, 0},
+		{"invalid token with refresh cookie", func(req *http.Request) {
+			bearer("invalid")(req)
+			req.AddCookie(&http.Cookie{Name: logic.RefreshCookieName, Value: refreshToken})
+		}, controller.CodeInvalidToken, invalidToken, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, challenge, userID := serveAuth(t, tc.setRequest)
+			if code != tc.wantCode || challenge != tc.wantChallenge || userID != tc.wantUserID {
+				t.Errorf("got %d, %q, %d, want %d, %q, %d", code, challenge, userID, tc.wantCode, tc.wantChallenge, tc.wantUserID)
+			}
+		})
+	}
+
+	// 会话被撤销后,未过期的accessToken也被拒绝
+	if err := redis.RevokeSessions(ctx, 1, []string{"s1"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if code, challenge, _ := serveAuth(t, bearer(accessToken)); code != controller.CodeInvalidToken || challenge != invalidToken {
+		t.Errorf("revoked session got %d, %q, want %d, %q", code, challenge, controller.CodeInvalidToken, invalidToken)
+	}
+}
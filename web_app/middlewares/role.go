@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"strconv"
+	"web_app/controller"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireRole 角色认证中间件,只允许指定角色的用户访问,需在JWTMiddleware之后使用
+func RequireRole(roles ...int8) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role, err := controller.GetCurrentUserRole(c)
+		if err != nil {
+			controller.ResponseError(c, controller.CodeNeedLogin)
+			c.Abort()
+			return
+		}
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		userID, _ := controller.GetCurrentUserID(c)
+		zap.L().Warn("permission denied",
+			zap.Int64("user_id", userID),
+			zap.Int8("role", role),
+			zap.String("path", c.FullPath()),
+		)
+		controller.ResponseError(c, controller.CodeNoPermission)
+		c.Abort()
+	}
+}
+
+// RequireCommunityModerator 社区版主认证中间件,允许管理员和路径参数param对应社区的版主访问,需在JWTMiddleware之后使用
+func RequireCommunityModerator(param string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role, err := controller.GetCurrentUserRole(c)
+		if err != nil {
+			controller.ResponseError(c, controller.CodeNeedLogin)
+			c.Abort()
+			return
+		}
+		if role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		communityID, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil {
+			controller.ResponseError(c, controller.CodeInvalidParam)
+			c.Abort()
+			return
+		}
+		if role == models.RoleModerator {
+			for _, id := range controller.GetCurrentUserCommunityIDs(c) {
+				if id == communityID {
+					c.Next()
+					return
+				}
+			}
+		}
+		userID, _ := controller.GetCurrentUserID(c)
+		zap.L().Warn("permission denied",
+			zap.Int64("user_id", userID),
+			zap.Int64("community_id", communityID),
+			zap.String("path", c.FullPath()),
+		)
+		controller.ResponseError(c, controller.CodeNoPermission)
+		c.Abort()
+	}
+}
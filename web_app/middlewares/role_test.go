@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"web_app/controller"
+	"web_app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveWithUser 模拟JWTMiddleware将用户信息存入上下文,返回经过middleware后的响应码
+func serveWithUser(t *testing.T, path, url string, setUser func(c *gin.Context), middleware gin.HandlerFunc) controller.ResCode {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(path, setUser, middleware, func(c *gin.Context) {
+		controller.ResponseSuccess(c, nil)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	var resp controller.ResponseData
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Code
+}
+
+// withUser 将用户角色和担任版主的社区存入上下文
+func withUser(role int8, communityIDs ...int64) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Set(controller.CtxUserIDKey, int64(1))
+		c.Set(controller.CtxUserRoleKey, role)
+		c.Set(controller.CtxCommunityIDsKey, communityIDs)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	cases := []struct {
+		name    string
+		setUser func(c *gin.Context)
+		want    controller.ResCode
+	}{
+		{"admin", withUser(models.RoleAdmin), controller.CodeSuccess},
+		{"moderator", withUser(models.RoleModerator, 1), controller.CodeNoPermission},
+		{"user", withUser(models.RoleUser), controller.CodeNoPermission},
+		{"not login", func(c *gin.Context) {}, controller.CodeNeedLogin},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := serveWithUser(t, "/admin", "/admin", tc.setUser, RequireRole(models.RoleAdmin))
+			if got != tc.want {
+				t.Errorf("code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequireCommunityModerator(t *testing.T) {
+	cases := []struct {
+		name    string
+		url     string
+		setUser func(c *gin.Context)
+		want    controller.ResCode
+	}{
+		{"admin", "/community/1", withUser(models.RoleAdmin), controller.CodeSuccess},
+		{"moderator of community", "/community/1", withUser(models.RoleModerator, 2, 1), controller.CodeSuccess},
+		{"moderator of other community", "/community/3", withUser(models.RoleModerator, 2, 1), controller.CodeNoPermission},
+		{"user", "/community/1", withUser(models.RoleUser), controller.CodeNoPermission},
+		{"invalid community id", "/community/abc", withUser(models.RoleModerator, 1), controller.CodeInvalidParam},
+		{"not login", "/community/1", func(c *gin.Context) {}, controller.CodeNeedLogin},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := serveWithUser(t, "/community/:id", tc.url, tc.setUser, RequireCommunityModerator("id"))
+			if got != tc.want {
+				t.Errorf("code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
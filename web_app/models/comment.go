@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	CommentStatusDeleted int8 = 0 // 评论已删除
+	CommentStatusNormal  int8 = 1 // 评论正常
+)
+
+type Comment struct {
+	CommentID  int64     `json:"comment_id,string" db:"comment_id"`
+	PostID     int64     `json:"post_id,string" db:"post_id"`
+	AuthorID   int64     `json:"author_id,string" db:"author_id"`
+	ParentID   int64     `json:"parent_id,string" db:"parent_id"` // 0表示直接回复帖子
+	Content    string    `json:"content" db:"content"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+type ApiComment struct {
+	AuthorName string `json:"author_name"`
+	ReplyNum   int64  `json:"reply_num"` // 回复该评论的评论数量
+	*Comment
+}
+
+type CommentsAndToken struct {
+	Token       string        `json:"token"`
+	CommentList []*ApiComment `json:"comment_list"`
+}
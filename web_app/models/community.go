@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	CommunityStatusArchived int8 = 0 // 社区已归档
+	CommunityStatusNormal   int8 = 1 // 社区正常
+)
+
 type Community struct {
 	CommunityID   int64  `json:"community_id" db:"community_id"`
 	CommunityName string `json:"community_name" db:"community_name"`
@@ -11,5 +16,13 @@ type CommunityDetail struct {
 	CommunityID   int64     `json:"community_id" db:"community_id"`
 	CommunityName string    `json:"community_name" db:"community_name"`
 	Introduction  string    `json:"introduction,omitempty" db:"introduction"`
+	Status        int8      `json:"status" db:"status"`
+	MemberNum     int64     `json:"member_num" db:"member_num"` // 社区成员数量
 	CreateTime    time.Time `json:"create_time" db:"create_time"`
 }
+
+// ApiCommunityAdmin 管理员查看的社区信息
+type ApiCommunityAdmin struct {
+	CommunityDetail
+	PostNum int64 `json:"post_num" db:"post_num"` // 社区中未删除的帖子数量
+}
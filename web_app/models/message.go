@@ -5,6 +5,7 @@ type CanalMessage struct {
 	Type     string                   `json:"type"`
 	Database string                   `json:"database"`
 	Table    string                   `json:"table"`
-	IdDdl    bool                     `json:"idDdl"`
+	IsDdl    bool                     `json:"isDdl"`
 	Data     []map[string]interface{} `json:"data"`
+	Old      []map[string]interface{} `json:"old"` // UPDATE 时被修改字段的旧值,与 Data 按下标一一对应
 }
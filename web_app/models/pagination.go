@@ -1,49 +1,73 @@
 package models
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
+const PageTokenVersion = 1 // 当前分页token版本,格式变化时递增使旧token失效
+
 type Page struct {
-	NextID        string `json:"next_id"`          // 下一篇帖子
-	NextTimeAtUTC int64  `json:"next_time_at_utc"` //  token过期时间
-	PageSize      int64  `json:"page_size"`        // 查询的帖子数量
+	Version       int     `json:"version"`          // token版本
+	Query         string  `json:"query"`            // 生成token的查询条件,使用时必须与当前查询一致
+	Prev          bool    `json:"prev"`             // 为true时获取游标之前的一页
+	NextID        string  `json:"next_id"`          // 游标,帖子列表中为上一页最后一篇帖子,评论列表中为下一条评论
+	NextScore     float64 `json:"next_score"`       // 游标帖子在排序ZSet中的分数,与NextID组成帖子列表的游标
+	NextTimeAtUTC int64   `json:"next_time_at_utc"` //  token过期时间
+	PageSize      int64   `json:"page_size"`        // 查询的帖子数量
 }
 
 type Token string
 
-// Encode 返回分页token
-func (p Page) Encode() Token {
+// Encode 返回分页token,格式为 base64(json).base64(HMAC-SHA256签名)
+func (p Page) Encode(secret []byte) Token {
+	p.Version = PageTokenVersion
 	b, err := json.Marshal(p)
 	if err != nil {
 		return Token("")
 	}
-	return Token(base64.StdEncoding.EncodeToString(b))
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return Token(payload + "." + base64.RawURLEncoding.EncodeToString(sign(secret, payload)))
 }
 
 // InValid判断page是否无效
 func (p Page) InValid() bool {
-	return p.NextID == "" || p.NextTimeAtUTC < time.Now().Unix() || p.PageSize <= 0
+	return p.Version != PageTokenVersion || p.NextID == "" || p.NextTimeAtUTC < time.Now().Unix() || p.PageSize <= 0
 }
 
-// Decode 解析分页信息
-func (t Token) Decode() Page {
+// Decode 校验签名并解析分页信息,签名不正确时返回空的Page
+func (t Token) Decode(secret []byte) Page {
 	var result Page
-	if len(t) == 0 {
+	payload, sigStr, ok := strings.Cut(string(t), ".")
+	if !ok {
+		return result
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigStr)
+	if err != nil || !hmac.Equal(sig, sign(secret, payload)) {
 		return result
 	}
 
-	bytes, err := base64.StdEncoding.DecodeString(string(t))
+	bytes, err := base64.RawURLEncoding.DecodeString(payload)
 	if err != nil {
 		return result
 	}
 
 	err = json.Unmarshal(bytes, &result)
 	if err != nil {
-		return result
+		return Page{}
 	}
 
 	return result
 }
+
+// sign 计算payload的HMAC-SHA256签名
+func sign(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -3,7 +3,8 @@ package models
 // ParamSignUp 注册请求的结构体
 type ParamSignUp struct {
 	Username   string `json:"username" binding:"required"`
-	Password   string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"omitempty,email,max=64" example:"gopher@example.com"` // 配置要求时必须填写
+	Password   string `json:"password" binding:"required,password"`
 	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
 }
 
@@ -13,6 +14,30 @@ type ParamLogin struct {
 	Password string `json:"password" binding:"required" example:"123"`
 }
 
+// ParamChangePassword 修改密码请求的结构体
+type ParamChangePassword struct {
+	OldPassword   string `json:"old_password" binding:"required"`
+	NewPassword   string `json:"new_password" binding:"required,password"`
+	ReNewPassword string `json:"re_new_password" binding:"required,eqfield=NewPassword"`
+}
+
+// ParamRequestPasswordReset 申请重置密码请求的结构体
+type ParamRequestPasswordReset struct {
+	Username string `json:"username" binding:"required" example:"juelan"`
+}
+
+// ParamResetPassword 重置密码请求的结构体
+type ParamResetPassword struct {
+	Token         string `json:"token" binding:"required"` // 邮件中的重置密码token
+	NewPassword   string `json:"new_password" binding:"required,password"`
+	ReNewPassword string `json:"re_new_password" binding:"required,eqfield=NewPassword"`
+}
+
+// ParamVerifyEmail 验证邮箱请求的结构体
+type ParamVerifyEmail struct {
+	Token string `json:"token" binding:"required"` // 邮件中的验证token
+}
+
 // ParamCommunity 创建社区请求的参数结构体
 type ParamCommunity struct {
 	CommuntiyID   int64  `json:"community_id,string" binding:"required"`
@@ -20,6 +45,30 @@ type ParamCommunity struct {
 	Introduction  string `json:"introduction" binding:"required"`
 }
 
+// ParamUpdateCommunity 编辑社区请求的参数结构体
+type ParamUpdateCommunity struct {
+	CommunityName string `json:"community_name" binding:"required"`
+	Introduction  string `json:"introduction" binding:"required"`
+}
+
+// ParamSetUserRole 设置用户角色的参数结构体,版主角色通过指派社区版主设置
+type ParamSetUserRole struct {
+	Role int8 `json:"role,string" binding:"oneof=0 2" example:"2"` //用户角色{0:普通用户,2:管理员}
+}
+
+// ParamUpdateProfile 编辑个人资料的参数结构体,未填写的字段会被清空
+type ParamUpdateProfile struct {
+	Email     string `json:"email" binding:"omitempty,email,max=64" example:"gopher@example.com"`
+	Gender    int8   `json:"gender" binding:"oneof=0 1 2" example:"1"` //性别{0:未知,1:男,2:女}
+	Bio       string `json:"bio" binding:"max=256"`
+	AvatarURL string `json:"avatar_url" binding:"omitempty,url,max=256"`
+}
+
+// ParamModerator 指派社区版主的参数结构体
+type ParamModerator struct {
+	UserID int64 `json:"user_id,string" binding:"required" example:"7549250837680128"` //用户ID
+}
+
 // ParamPost 创建帖子请求的参数结构体
 type ParamPost struct {
 	Title       string `json:"title" binding:"required"`
@@ -27,6 +76,12 @@ type ParamPost struct {
 	CommunityID int64  `json:"community_id,string" binding:"required"`
 }
 
+// ParamUpdatePost 编辑帖子请求的参数结构体
+type ParamUpdatePost struct {
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
 // ParamVoteForPost 给帖子投票的参数
 type ParamVoteForPost struct {
 	VoteType int8 `json:"vote_type,string" binding:"oneof=1 0 -1" example:"1"` //投票参数{1,0,-1}
@@ -36,7 +91,46 @@ type ParamVoteForPost struct {
 
 // ParamGetPosts获取帖子列表参数
 type ParamGetPostsInOrder struct {
-	CommunityID int64  `json:"community_id,string" form:"community_id" binding:"required" example:"1"`
-	Token       string `json:"token" form:"token"`
-	Order       string `json:"order" form:"order" example:"score"`
+	CommunityID     int64  `json:"community_id,string" form:"community_id" example:"1"` //社区ID,不填时获取全站帖子
+	Token           string `json:"token" form:"token"`
+	Order           string `json:"order" form:"order" example:"score"`
+	PageSize        int64  `json:"page_size" form:"page_size" binding:"omitempty,min=1" example:"10"` //每页数量,不填时使用默认值,不能超过配置的最大值
+	ExcludeArchived bool   `json:"exclude_archived" form:"exclude_archived"`                          //获取全站帖子时是否排除已归档社区的帖子
+}
+
+// ParamGetTimeline 获取关注的人发布的帖子列表的参数结构体
+type ParamGetTimeline struct {
+	Token    string `json:"token" form:"token"`
+	PageSize int64  `json:"page_size" form:"page_size" binding:"omitempty,min=1" example:"10"` //每页数量,不填时使用默认值,不能超过配置的最大值
+}
+
+// ParamGetUserPosts 获取用户发布的帖子列表的参数结构体
+type ParamGetUserPosts struct {
+	Token    string `json:"token" form:"token"`
+	PageSize int64  `json:"page_size" form:"page_size" binding:"omitempty,min=1" example:"10"` //每页数量,不填时使用默认值,不能超过配置的最大值
+}
+
+// ParamGetFeed 获取用户加入的社区的帖子列表的参数结构体
+type ParamGetFeed struct {
+	Token    string `json:"token" form:"token"`
+	Order    string `json:"order" form:"order" example:"score"`
+	PageSize int64  `json:"page_size" form:"page_size" binding:"omitempty,min=1" example:"10"` //每页数量,不填时使用默认值,不能超过配置的最大值
+}
+
+// ParamGetLeaderboard 获取karma排行榜的参数结构体
+type ParamGetLeaderboard struct {
+	CommunityID int64 `json:"community_id,string" form:"community_id" example:"1"`               //社区ID,不填时获取全站排行榜
+	Limit       int64 `json:"limit" form:"limit" binding:"omitempty,min=1,max=100" example:"10"` //返回的用户数量,默认10
+}
+
+// ParamComment 发表评论的参数结构体
+type ParamComment struct {
+	Content  string `json:"content" binding:"required,max=2048"`
+	ParentID int64  `json:"parent_id,string" example:"0"` //回复的评论ID,不填表示直接回复帖子
+}
+
+// ParamGetComments 获取评论列表参数
+type ParamGetComments struct {
+	ParentID int64  `json:"parent_id,string" form:"parent_id" example:"0"` //父评论ID,不填表示获取直接回复帖子的评论
+	Token    string `json:"token" form:"token"`
 }
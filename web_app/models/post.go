@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	PostStatusDeleted int32 = 0 // 帖子已删除
+	PostStatusNormal  int32 = 1 // 帖子正常
+)
+
 type Post struct {
 	PostID      int64     `json:"post_id,string" db:"post_id"`
 	AuthorID    int64     `json:"author_id,string" db:"author_id"`
@@ -12,14 +17,24 @@ type Post struct {
 	CreatTime   time.Time `json:"create_time" db:"create_time"`
 }
 
+const (
+	OrderTime  = "time"  // 按发帖时间排序
+	OrderScore = "score" // 按发帖时间加投票分数排序,默认排序方式
+)
+
 type ApiPostDetail struct {
 	AuthorName string `json:"author_name"`
-	VoteNum    int64  `json:"vote_num"`
+	VoteNum    int64  `json:"vote_num"` // 赞成票减反对票
+	UpNum      int64  `json:"up_num"`
+	DownNum    int64  `json:"down_num"`
+	CommentNum int64  `json:"comment_num"`
 	*Post
 	*CommunityDetail `json:"community"`
 }
 
 type PostsAndToken struct {
-	Token    string           `json:"token"`
-	PostList []*ApiPostDetail `json:"post_list"`
+	NextToken string           `json:"next_token"` // 下一页的pageToken,没有下一页时为空
+	PrevToken string           `json:"prev_token"` // 上一页的pageToken,没有上一页时为空
+	HasMore   bool             `json:"has_more"`   // 是否还有下一页
+	PostList  []*ApiPostDetail `json:"post_list"`
 }
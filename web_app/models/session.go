@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Session 用户的登录会话,每次登录创建一个会话,会话ID写入token的jti
+type Session struct {
+	SessionID      string    `json:"session_id"`
+	UserAgent      string    `json:"user_agent"`
+	IP             string    `json:"ip"`
+	CreateTime     time.Time `json:"create_time"`
+	LastActiveTime time.Time `json:"last_active_time"` // 最近一次刷新AccessToken的时间
+	Current        bool      `json:"current"`          // 是否为当前请求使用的会话
+	RefreshToken   string    `json:"-"`
+}
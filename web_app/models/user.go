@@ -1,7 +1,47 @@
 package models
 
+// 用户角色
+const (
+	RoleUser      int8 = 0 // 普通用户
+	RoleModerator int8 = 1 // 社区版主,只能管理被指派的社区
+	RoleAdmin     int8 = 2 // 管理员
+)
+
+// 用户性别
+const (
+	GenderUnknown int8 = 0 // 未知
+	GenderMale    int8 = 1 // 男
+	GenderFemale  int8 = 2 // 女
+)
+
 type User struct {
 	UserID   int64  `json:"user_id" db:"user_id"`
 	Username string `json:"username" db:"username"`
 	Password string `json:"password" db:"password"`
+	Email    string `json:"email" db:"email"`
+	Role     int8   `json:"role" db:"role"`
+}
+
+// UserProfile 用户可以编辑的个人资料
+type UserProfile struct {
+	UserID        int64  `json:"user_id,string" db:"user_id"`
+	Username      string `json:"username" db:"username"`
+	Email         string `json:"email" db:"email"`
+	EmailVerified bool   `json:"email_verified" db:"email_verified"`
+	Gender        int8   `json:"gender" db:"gender"`
+	Bio           string `json:"bio" db:"bio"`
+	AvatarURL     string `json:"avatar_url" db:"avatar_url"`
+}
+
+// ApiUserProfile 用户主页信息,不包含邮箱
+type ApiUserProfile struct {
+	UserID       int64  `json:"user_id,string"`
+	Username     string `json:"username"`
+	Gender       int8   `json:"gender"`
+	Bio          string `json:"bio"`
+	AvatarURL    string `json:"avatar_url"`
+	PostNum      int64  `json:"post_num"`      // 发布的帖子数量
+	Karma        int64  `json:"karma"`         // 发布的帖子获得的赞成票减反对票
+	FollowerNum  int64  `json:"follower_num"`  // 粉丝数量
+	FollowingNum int64  `json:"following_num"` // 关注的人数
 }
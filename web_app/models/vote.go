@@ -4,8 +4,38 @@ import "time"
 
 // VotePost 帖子投票结构体
 type VotePost struct {
-	PostID     int64     `json:"post_id" db:"post_id"`
-	UserID     int64     `json:"user_id" db:"user_id"`
-	VoteType   int8      `json:"vote_type" db:"vote_type"`
-	CreateTime time.Time `json:"create_time" db:"create_time"`
+	PostID      int64     `json:"post_id" db:"post_id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
+	VoteType    int8      `json:"vote_type" db:"vote_type"`
+	OldVoteType int8      `json:"old_vote_type" db:"-"` // 投票前的投票类型,用于计算作者karma的变化
+	CreateTime  time.Time `json:"create_time" db:"create_time"`
+}
+
+// VoteNum 帖子赞成票和反对票数量
+type VoteNum struct {
+	PostID  int64 `json:"post_id" db:"post_id"`
+	UpNum   int64 `json:"up_num" db:"up_num"`
+	DownNum int64 `json:"down_num" db:"down_num"`
+}
+
+// PostVoteArchive 投票关闭后归档的帖子投票结果
+type PostVoteArchive struct {
+	PostID  int64   `json:"post_id" db:"post_id"`
+	Score   float64 `json:"score" db:"score"`
+	UpNum   int64   `json:"up_num" db:"up_num"`
+	DownNum int64   `json:"down_num" db:"down_num"`
+}
+
+// UserKarma 作者在社区中的帖子获得的赞成票减反对票
+type UserKarma struct {
+	UserID      int64 `json:"user_id" db:"user_id"`
+	CommunityID int64 `json:"community_id" db:"community_id"`
+	Karma       int64 `json:"karma" db:"karma"`
+}
+
+// ApiUserKarma karma排行榜中的用户
+type ApiUserKarma struct {
+	UserID   int64  `json:"user_id,string"`
+	Username string `json:"username"`
+	Karma    int64  `json:"karma"`
 }
@@ -4,52 +4,88 @@ import (
 	"errors"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 var ErrorInvalidToken = errors.New("invalid token")
 
-var mySecret = []byte("不能说的")
+const (
+	Issuer               = "lightning"         // token签发者
+	AccessTokenAudience  = "lightning_access"  // accessToken的受众,用于访问接口
+	RefreshTokenAudience = "lightning_refresh" // refreshToken的受众,只用于刷新accessToken
+)
+
+// UserInfo 存入token的用户信息
+type UserInfo struct {
+	UserID       int64   `json:"user_id"`
+	Username     string  `json:"username"`
+	Role         int8    `json:"role"`
+	CommunityIDs []int64 `json:"community_ids,omitempty"` // 版主管理的社区id
+}
 
 type MyClaims struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"user_name"`
-	jwt.StandardClaims
+	UserInfo
+	Generation int64 `json:"generation,omitempty"` // RefreshToken在会话中的轮换次数
+	jwt.RegisteredClaims
 }
 
-// genToken 生成JWT
-func genToken(user_id int64, username string, time_duration time.Duration, issuer string) (string, error) {
+// genToken 生成JWT,jti为登录会话ID,audience区分token类型
+func genToken(info UserInfo, sessionID string, generation int64, time_duration time.Duration, audience string) (string, error) {
+	if signingKey == nil {
+		return "", ErrorNoSigningKey
+	}
 	// 创建一个MyClaims
 	claims := &MyClaims{
-		user_id,
-		username,
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time_duration).Unix(),
-			Issuer:    issuer,
+		info,
+		generation,
+		jwt.RegisteredClaims{
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time_duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{audience},
 		},
 	}
-	// 使用指定的签名方法创建签名对象
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// 使用指定的secret签名并获得完整的编码后的字符串token
-	return token.SignedString(mySecret)
+	// 使用签名密钥的算法创建签名对象,头部写入kid以便验证时选择密钥
+	token := jwt.NewWithClaims(signingKey.method, claims)
+	token.Header["kid"] = signingKey.kid
+	// 使用指定的密钥签名并获得完整的编码后的字符串token
+	return token.SignedString(signingKey.signKey)
 }
 
 // GenAccessToken 生成JWT AccessToken
-func GenAccessToken(user_id int64, username string, access_time_duration time.Duration) (string, error) {
-	return genToken(user_id, username, access_time_duration, "access_token_issuer")
+func GenAccessToken(info UserInfo, sessionID string, access_time_duration time.Duration) (string, error) {
+	return genToken(info, sessionID, 0, access_time_duration, AccessTokenAudience)
+}
+
+// GenRefreshToken 生成JWT RefreshToken,每次轮换generation加一,保证同一会话的RefreshToken互不相同
+func GenRefreshToken(info UserInfo, sessionID string, generation int64, refresh_time_duration time.Duration) (string, error) {
+	return genToken(info, sessionID, generation, refresh_time_duration, RefreshTokenAudience)
+}
+
+// ParseAccessToken 解析accessToken,签发者或受众不符的token返回错误
+func ParseAccessToken(tokenString string) (*MyClaims, error) {
+	return parseToken(tokenString, AccessTokenAudience)
 }
 
-// GenRefreshToken 生成JWT RefreshToken
-func GenRefreshToken(user_id int64, username string, refresh_time_duration time.Duration) (string, error) {
-	return genToken(user_id, username, refresh_time_duration, "refresh_token_issuer")
+// ParseRefreshToken 解析refreshToken,签发者或受众不符的token返回错误
+func ParseRefreshToken(tokenString string) (*MyClaims, error) {
+	return parseToken(tokenString, RefreshTokenAudience)
 }
 
-// ParseToken解析tokenString
-func ParseToken(tokenString string) (*MyClaims, error) {
+// parseToken解析tokenString,根据头部的kid选择验证密钥,并校验签发者和受众
+func parseToken(tokenString, audience string) (*MyClaims, error) {
 	mc := new(MyClaims)
-	token, err := jwt.ParseWithClaims(tokenString, mc, func(t *jwt.Token) (interface{}, error) {
-		return mySecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, mc, keyFunc,
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodHS256.Alg(),
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodEdDSA.Alg(),
+		}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(audience),
+	)
 	if err != nil {
 		return nil, err
 	}
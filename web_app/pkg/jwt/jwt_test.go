@@ -0,0 +1,194 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+	"web_app/settings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testRSAKeyID  = "rsa-test"
+	testHMACKeyID = "hs-test"
+	testSecret    = "0123456789abcdef0123456789abcdef"
+)
+
+var testUser = UserInfo{UserID: 1, Username: "tester"}
+
+// setupKeys 加载一个RS256签名密钥和一个HS256密钥,返回RSA公钥的PEM内容
+func setupKeys(t *testing.T) (publicPEM []byte) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+
+	err = Init(&settings.JWTConfig{
+		SigningKeyID: testRSAKeyID,
+		Keys: []*settings.JWTKeyConfig{
+			{KID: testRSAKeyID, Algorithm: "RS256", PrivateKey: string(privPEM)},
+			{KID: testHMACKeyID, Algorithm: "HS256", Secret: testSecret},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { keys, signingKey = nil, nil })
+	return publicPEM
+}
+
+// validClaims 返回一个能通过校验的claims,由调用方修改其中的字段
+func validClaims(audience string) *MyClaims {
+	return &MyClaims{
+		UserInfo: testUser,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{audience},
+		},
+	}
+}
+
+// signToken 使用指定的算法、kid和密钥签发token
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, signKey interface{}, claims *MyClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestParseToken(t *testing.T) {
+	setupKeys(t)
+
+	access, err := GenAccessToken(testUser, "session", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := GenRefreshToken(testUser, "session", 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc, err := ParseAccessToken(access)
+	if err != nil {
+		t.Fatalf("ParseAccessToken(access) failed: %v", err)
+	}
+	if mc.UserID != testUser.UserID || mc.ID != "session" {
+		t.Errorf("ParseAccessToken(access) = %+v", mc)
+	}
+	mc, err = ParseRefreshToken(refresh)
+	if err != nil {
+		t.Fatalf("ParseRefreshToken(refresh) failed: %v", err)
+	}
+	if mc.Generation != 1 {
+		t.Errorf("generation = %d, want 1", mc.Generation)
+	}
+
+	// refreshToken不能作为accessToken使用,反之亦然
+	if _, err = ParseAccessToken(refresh); err == nil {
+		t.Error("refresh token accepted as access token")
+	}
+	if _, err = ParseRefreshToken(access); err == nil {
+		t.Error("access token accepted as refresh token")
+	}
+}
+
+func TestParseTokenRejected(t *testing.T) {
+	publicPEM := setupKeys(t)
+	rsaSignKey := signingKey.signKey
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"wrong issuer", func() string {
+			mc := validClaims(AccessTokenAudience)
+			mc.Issuer = "other"
+			return signToken(t, jwt.SigningMethodRS256, testRSAKeyID, rsaSignKey, mc)
+		}},
+		{"wrong audience", func() string {
+			return signToken(t, jwt.SigningMethodRS256, testRSAKeyID, rsaSignKey, validClaims("other"))
+		}},
+		{"missing audience", func() string {
+			mc := validClaims(AccessTokenAudience)
+			mc.Audience = nil
+			return signToken(t, jwt.SigningMethodRS256, testRSAKeyID, rsaSignKey, mc)
+		}},
+		{"expired", func() string {
+			mc := validClaims(AccessTokenAudience)
+			mc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return signToken(t, jwt.SigningMethodRS256, testRSAKeyID, rsaSignKey, mc)
+		}},
+		{"missing expiration", func() string {
+			mc := validClaims(AccessTokenAudience)
+			mc.ExpiresAt = nil
+			return signToken(t, jwt.SigningMethodRS256, testRSAKeyID, rsaSignKey, mc)
+		}},
+		{"alg none", func() string {
+			return signToken(t, jwt.SigningMethodNone, testHMACKeyID, jwt.UnsafeAllowNoneSignatureType, validClaims(AccessTokenAudience))
+		}},
+		{"hs256 signed with rsa public key", func() string {
+			return signToken(t, jwt.SigningMethodHS256, testRSAKeyID, publicPEM, validClaims(AccessTokenAudience))
+		}},
+		{"unknown kid", func() string {
+			return signToken(t, jwt.SigningMethodHS256, "unknown", []byte(testSecret), validClaims(AccessTokenAudience))
+		}},
+		{"missing kid", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims(AccessTokenAudience))
+			s, err := token.SignedString([]byte(testSecret))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+		{"wrong hmac secret", func() string {
+			return signToken(t, jwt.SigningMethodHS256, testHMACKeyID, []byte(testSecret+"x"), validClaims(AccessTokenAudience))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if mc, err := ParseAccessToken(tt.token()); err == nil {
+				t.Errorf("token accepted: %+v", mc)
+			}
+		})
+	}
+}
+
+func TestParseTokenHMACKey(t *testing.T) {
+	setupKeys(t)
+	// 验证密钥集合中的HS256密钥签发的token有效
+	token := signToken(t, jwt.SigningMethodHS256, testHMACKeyID, []byte(testSecret), validClaims(AccessTokenAudience))
+	if _, err := ParseAccessToken(token); err != nil {
+		t.Fatalf("ParseAccessToken failed: %v", err)
+	}
+}
+
+func TestInitShortSecret(t *testing.T) {
+	t.Cleanup(func() { keys, signingKey = nil, nil })
+	err := Init(&settings.JWTConfig{
+		SigningKeyID: testHMACKeyID,
+		Keys: []*settings.JWTKeyConfig{
+			{KID: testHMACKeyID, Algorithm: "HS256", Secret: testSecret[:minSecretLength-1]},
+		},
+	})
+	if err == nil {
+		t.Fatal("short HS256 secret accepted")
+	}
+}
@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"web_app/settings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minSecretLength HS256密钥的最小长度,与SHA-256的输出长度一致
+const minSecretLength = 32
+
+var (
+	ErrorNoSigningKey = errors.New("未配置JWT签名密钥")
+	ErrorUnknownKeyID = errors.New("未知的JWT密钥kid")
+)
+
+// key 一个JWT密钥,只配置公钥的密钥不能签发token
+type key struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{} // HMAC密钥或私钥
+	verifyKey interface{} // HMAC密钥或公钥
+}
+
+var (
+	keys       map[string]*key // kid -> 密钥
+	signingKey *key            // 签发新token使用的密钥
+)
+
+// Init 加载JWT密钥,cfg.SigningKeyID指定的密钥用于签发token,所有密钥都可用于验证token
+func Init(cfg *settings.JWTConfig) (err error) {
+	if cfg == nil || len(cfg.Keys) == 0 {
+		return ErrorNoSigningKey
+	}
+	loaded := make(map[string]*key, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		k, err := loadKey(kc)
+		if err != nil {
+			return fmt.Errorf("load jwt key %q failed: %w", kc.KID, err)
+		}
+		if _, ok := loaded[k.kid]; ok {
+			return fmt.Errorf("duplicate jwt key %q", k.kid)
+		}
+		loaded[k.kid] = k
+	}
+	sk, ok := loaded[cfg.SigningKeyID]
+	if !ok || sk.signKey == nil {
+		return ErrorNoSigningKey
+	}
+	keys, signingKey = loaded, sk
+	return nil
+}
+
+// loadKey 根据配置解析密钥
+func loadKey(kc *settings.JWTKeyConfig) (k *key, err error) {
+	if kc.KID == "" {
+		return nil, errors.New("kid is empty")
+	}
+	k = &key{kid: kc.KID}
+	switch kc.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		secret := os.ExpandEnv(kc.Secret)
+		if secret == "" {
+			return nil, errors.New("secret is empty")
+		}
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("secret must be at least %d bytes", minSecretLength)
+		}
+		k.method = jwt.SigningMethodHS256
+		k.signKey = []byte(secret)
+		k.verifyKey = k.signKey
+	case jwt.SigningMethodRS256.Alg():
+		k.method = jwt.SigningMethodRS256
+		err = loadAsymmetricKey(k, kc,
+			func(pem []byte) (interface{}, interface{}, error) {
+				priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+				if err != nil {
+					return nil, nil, err
+				}
+				return priv, &priv.PublicKey, nil
+			},
+			func(pem []byte) (interface{}, error) { return jwt.ParseRSAPublicKeyFromPEM(pem) },
+		)
+	case jwt.SigningMethodEdDSA.Alg():
+		k.method = jwt.SigningMethodEdDSA
+		err = loadAsymmetricKey(k, kc,
+			func(pem []byte) (interface{}, interface{}, error) {
+				priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+				if err != nil {
+					return nil, nil, err
+				}
+				return priv, priv.(ed25519.PrivateKey).Public(), nil
+			},
+			func(pem []byte) (interface{}, error) { return jwt.ParseEdPublicKeyFromPEM(pem) },
+		)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", kc.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// loadAsymmetricKey 加载私钥和公钥,配置了私钥时公钥由私钥导出
+func loadAsymmetricKey(k *key, kc *settings.JWTKeyConfig,
+	parsePrivate func([]byte) (interface{}, interface{}, error),
+	parsePublic func([]byte) (interface{}, error),
+) (err error) {
+	privPEM, err := readPEM(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	if privPEM != nil {
+		k.signKey, k.verifyKey, err = parsePrivate(privPEM)
+		return err
+	}
+	pubPEM, err := readPEM(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	if pubPEM == nil {
+		return errors.New("private key or public key is required")
+	}
+	k.verifyKey, err = parsePublic(pubPEM)
+	return err
+}
+
+// readPEM 读取PEM内容,优先使用直接配置的内容,其次读取文件
+func readPEM(content, file string) ([]byte, error) {
+	if content = os.ExpandEnv(content); content != "" {
+		return []byte(content), nil
+	}
+	if file = os.ExpandEnv(file); file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// keyFunc 根据token头部的kid查找验证密钥,并校验签名算法与密钥一致
+func keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := keys[kid]
+	if !ok {
+		return nil, ErrorUnknownKeyID
+	}
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, ErrorInvalidToken
+	}
+	return k.verifyKey, nil
+}
+
+// JSONWebKey JWKS中的一个公钥
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA模数
+	E   string `json:"e,omitempty"`   // RSA指数
+	Crv string `json:"crv,omitempty"` // EdDSA曲线
+	X   string `json:"x,omitempty"`   // EdDSA公钥
+}
+
+// JSONWebKeySet 公开的JWT验证公钥集合
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeySet 获得所有非对称密钥的公钥,HMAC密钥不公开
+func PublicKeySet() *JSONWebKeySet {
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+	for _, k := range keys {
+		jwk := JSONWebKey{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"web_app/settings"
+
+	"go.uber.org/zap"
+)
+
+// Message 一封邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+var defaultMailer Mailer = new(logMailer)
+
+// Init 根据配置选择邮件发送方式
+func Init(cfg *settings.MailerConfig) (err error) {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Driver {
+	case "", "log":
+		defaultMailer = new(logMailer)
+	case "smtp":
+		defaultMailer = newSMTPMailer(cfg)
+	case "memory":
+		defaultMailer = NewMemoryMailer()
+	default:
+		return fmt.Errorf("unsupported mailer driver %q", cfg.Driver)
+	}
+	return nil
+}
+
+// SetMailer 替换邮件发送方式,用于测试时注入 MemoryMailer
+func SetMailer(m Mailer) {
+	defaultMailer = m
+}
+
+// Send 使用配置的发送方式发送邮件
+func Send(ctx context.Context, msg *Message) error {
+	return defaultMailer.Send(ctx, msg)
+}
+
+// logMailer 只将邮件内容写入日志,用于本地开发
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	zap.L().Info("mailer send",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
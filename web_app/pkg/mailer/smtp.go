@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"web_app/settings"
+)
+
+// smtpMailer 通过SMTP服务器发送邮件
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func newSMTPMailer(cfg *settings.MailerConfig) *smtpMailer {
+	m := &smtpMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	header := []string{
+		"From: " + m.from,
+		"To: " + msg.To,
+		"Subject: " + mimeEncode(msg.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	body := strings.Join(header, "\r\n") + "\r\n\r\n" + msg.Body
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(body))
+}
+
+// mimeEncode 编码包含中文的邮件标题
+func mimeEncode(s string) string {
+	return mime.BEncoding.Encode("UTF-8", s)
+}
@@ -4,6 +4,7 @@ import (
 	"web_app/controller"
 	"web_app/logger"
 	"web_app/middlewares"
+	"web_app/models"
 	"web_app/settings"
 
 	"github.com/gin-gonic/gin"
@@ -19,12 +20,22 @@ func Setup(mode string, cfg *settings.RatelimitConfig) *gin.Engine {
 	r := gin.New()
 	r.Use(logger.GinLogger(), logger.GinRecovery(true))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// JWT公钥集合,供其他服务验证token
+	r.GET("/.well-known/jwks.json", controller.JWKSHandler)
 	v2 := r.Group("/api/v2")
 	{
 		// 注册功能
 		v2.POST("/signup", controller.SignUpHandler)
 		// 登录功能
 		v2.POST("/login", controller.LoginHandler)
+		// 刷新AccessToken功能
+		v2.POST("/token/refresh", controller.RefreshTokenHandler)
+		// 申请重置密码功能
+		v2.POST("/password/reset/request", controller.RequestPasswordResetHandler)
+		// 重置密码功能
+		v2.POST("/password/reset", controller.ResetPasswordHandler)
+		// 验证邮箱功能
+		v2.POST("/verify-email", controller.VerifyEmailHandler)
 		// 查看社区列表功能
 		v2.GET("/community", controller.GetCommunityListHandler)
 		// 查看社区详细信息功能
@@ -33,21 +44,92 @@ func Setup(mode string, cfg *settings.RatelimitConfig) *gin.Engine {
 		v2.GET("/post/:id", controller.GetPostDetailHandler)
 		// 查看帖子列表功能
 		v2.GET("/posts", controller.GetPostListHandler)
+		// 查看帖子评论功能
+		v2.GET("/post/:id/comments", controller.GetCommentListHandler)
+		// 查看用户主页功能
+		v2.GET("/user/:id", controller.GetUserProfileHandler)
+		// 查看用户发布的帖子功能
+		v2.GET("/user/:id/posts", controller.GetUserPostListHandler)
+		// 查看karma排行榜功能
+		v2.GET("/leaderboard/users", controller.GetKarmaLeaderboardHandler)
 		// 使用jwt认证中间件
 		v2.Use(middlewares.JWTMiddleware(), middlewares.RateLimitMiddleware(cfg.FillInterval, cfg.Cap))
 		// 创建帖子功能
 		v2.POST("/post", controller.CreatePostHandler)
+		// 编辑帖子功能
+		v2.PUT("/post/:id", controller.UpdatePostHandler)
+		// 删除帖子功能
+		v2.DELETE("/post/:id", controller.DeletePostHandler)
+		// 发表评论功能
+		v2.POST("/post/:id/comments", controller.CreateCommentHandler)
 		// 投票功能
 		v2.POST("/vote", controller.VoteForPostHandler)
+		// 加入社区功能
+		v2.POST("/community/:id/join", controller.JoinCommunityHandler)
+		// 退出社区功能
+		v2.DELETE("/community/:id/join", controller.LeaveCommunityHandler)
+		// 查看我加入的社区功能
+		v2.GET("/me/communities", controller.GetJoinedCommunityListHandler)
+		// 查看我加入的社区的帖子功能
+		v2.GET("/feed", controller.GetFeedHandler)
+		// 查看我的资料功能
+		v2.GET("/me", controller.GetMyProfileHandler)
+		// 编辑我的资料功能
+		v2.PUT("/me", controller.UpdateMyProfileHandler)
+		// 修改密码功能
+		v2.PUT("/me/password", controller.ChangePasswordHandler)
+		// 重新发送验证邮件功能
+		v2.POST("/me/verify-email", controller.SendVerifyEmailHandler)
+		// 关注用户功能
+		v2.POST("/user/:id/follow", controller.FollowHandler)
+		// 取消关注用户功能
+		v2.DELETE("/user/:id/follow", controller.UnfollowHandler)
+		// 查看关注的人的帖子功能
+		v2.GET("/timeline", controller.GetTimelineHandler)
+		// 退出登录功能
+		v2.POST("/logout", controller.LogoutHandler)
+		// 查看登录会话功能
+		v2.GET("/sessions", controller.GetSessionListHandler)
+		// 撤销登录会话功能
+		v2.DELETE("/sessions/:id", controller.RevokeSessionHandler)
+		// 退出所有设备功能
+		v2.DELETE("/sessions", controller.RevokeAllSessionsHandler)
 
+		// 社区版主接口,管理员和该社区的版主可以访问
+		mod := v2.Group("/mod/community/:id", middlewares.RequireCommunityModerator("id"))
+		{
+			// 编辑社区
+			mod.PUT("", controller.UpdateCommunityHandler)
+			// 删除社区中的帖子
+			mod.DELETE("/post/:post_id", controller.ModDeletePostHandler)
+		}
 	}
 	// 管理员接口
 	admin := r.Group("/admin")
+	admin.Use(middlewares.JWTMiddleware(), middlewares.RequireRole(models.RoleAdmin))
 	{
 		// // 将mysql中的社区ids更新到redis中
 		// admin.PUT("/set/community/ids", controller.SetCommunityIDsInRedisHandler)
 		// 创建新社区
 		admin.POST("/add/community", controller.CreateCommunityHandler)
+		// 编辑社区
+		admin.PUT("/update/community/:id", controller.UpdateCommunityHandler)
+		// 归档社区
+		admin.PUT("/archive/community/:id", controller.ArchiveCommunityHandler)
+		// 取消归档社区
+		admin.PUT("/unarchive/community/:id", controller.UnarchiveCommunityHandler)
+		// 查看所有社区及帖子数量
+		admin.GET("/list/community", controller.GetAdminCommunityListHandler)
+		// 设置用户角色
+		admin.PUT("/set/user/role/:id", controller.SetUserRoleHandler)
+		// 指派社区版主
+		admin.POST("/add/community/moderator/:id", controller.AddModeratorHandler)
+		// 取消社区版主
+		admin.DELETE("/delete/community/moderator/:id/:user_id", controller.RemoveModeratorHandler)
+		// 从数据库重建帖子投票数量
+		admin.PUT("/rebuild/vote/num", controller.RebuildVoteNumHandler)
+		// 从数据库重建用户karma
+		admin.PUT("/rebuild/karma", controller.RebuildKarmaHandler)
 	}
 	return r
 }
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -20,11 +21,21 @@ type AppConf struct {
 	Port                 int           `mapstructure:"port"`
 	AccessTokenDuration  time.Duration `mapstructure:"access_token_duration"`
 	RefreshTokenDuration time.Duration `mapstructure:"refresh_token_duration"`
+	RefreshTokenGrace    time.Duration `mapstructure:"refresh_token_grace"` // 已轮换的RefreshToken在该时长内仍可换取已签发的新token
+	VoteWindow           time.Duration `mapstructure:"vote_window"`         // 帖子发布后允许投票的时长,0表示不限制
+	PageTokenSecret      string        `mapstructure:"page_token_secret"`   // 分页token的签名密钥
+	MaxPageSize          int64         `mapstructure:"max_page_size"`       // 每页最多显示数量
 	*LogConfig           `mapstructure:"log"`
 	*MysqlConfig         `mapstructure:"mysql"`
 	*RedisConfig         `mapstructure:"redis"`
 	*KafkaConfig         `mapstructure:"kafka"`
 	*RatelimitConfig     `mapstructure:"ratelimit"`
+	*RankingConfig       `mapstructure:"ranking"`
+	*TimelineConfig      `mapstructure:"timeline"`
+	*JWTConfig           `mapstructure:"jwt"`
+	*PasswordConfig      `mapstructure:"password"`
+	*MailerConfig        `mapstructure:"mailer"`
+	*AccountConfig       `mapstructure:"account"`
 }
 
 type LogConfig struct {
@@ -58,10 +69,64 @@ type KafkaConfig struct {
 	GroupIDCommunity string   `mapstructure:"group_id_community"`
 	GroupIDPost      string   `mapstructure:"group_id_post"`
 	GroupIDVotePost  string   `mapstructure:"group_id_vote_post"`
+	GroupIDKarma     string   `mapstructure:"group_id_karma"` // karma消费者组,与投票持久化消费同一个topic
 	TopicCommunity   string   `mapstructure:"topic_community"`
 	TopicPost        string   `mapstructure:"topic_post"`
 	TopicVotePost    string   `mapstructure:"topic_vote_post"`
 }
+type RankingConfig struct {
+	Strategies      []string      `mapstructure:"strategies"`       // 启用的排序策略,score始终启用
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // 重新计算帖子排名的间隔
+	RefreshWindow   time.Duration `mapstructure:"refresh_window"`   // 定期计算时只重新计算该时长内发布的帖子在分数随时间变化的策略下的分数
+}
+
+type TimelineConfig struct {
+	FanOutThreshold int64 `mapstructure:"fan_out_threshold"` // 粉丝数不超过该值的作者发帖时推送到粉丝的时间线,否则粉丝读取时间线时拉取
+	MaxLen          int64 `mapstructure:"max_len"`           // 每个用户的时间线保留的帖子数量
+}
+
+type JWTConfig struct {
+	SigningKeyID string          `mapstructure:"signing_kid"` // 签发新token使用的密钥kid
+	Keys         []*JWTKeyConfig `mapstructure:"keys"`        // 所有有效的密钥,轮换期间旧密钥只用于验证
+}
+
+// JWTKeyConfig JWT密钥配置,取值支持${ENV}形式引用环境变量
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`        // HS256、RS256或EdDSA
+	Secret         string `mapstructure:"secret"`           // HS256的密钥
+	PrivateKey     string `mapstructure:"private_key"`      // PEM格式私钥,用于签发token
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM格式私钥文件路径
+	PublicKey      string `mapstructure:"public_key"`       // PEM格式公钥,没有私钥时只用于验证token
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM格式公钥文件路径
+}
+
+type PasswordConfig struct {
+	MinLength          int           `mapstructure:"min_length"`           // 密码最小长度
+	MaxLength          int           `mapstructure:"max_length"`           // 密码最大长度,bcrypt只使用前72个字节
+	RequireUpper       bool          `mapstructure:"require_upper"`        // 是否必须包含大写字母
+	RequireLower       bool          `mapstructure:"require_lower"`        // 是否必须包含小写字母
+	RequireDigit       bool          `mapstructure:"require_digit"`        // 是否必须包含数字
+	RequireSymbol      bool          `mapstructure:"require_symbol"`       // 是否必须包含特殊字符
+	ResetTokenDuration time.Duration `mapstructure:"reset_token_duration"` // 重置密码token的有效期
+}
+
+type MailerConfig struct {
+	Driver   string `mapstructure:"driver"` // 邮件发送方式,log只将邮件写入日志,smtp通过SMTP服务器发送,memory保存在内存中用于测试
+	From     string `mapstructure:"from"`   // 发件人地址
+	Host     string `mapstructure:"host"`   // SMTP服务器地址
+	Port     int    `mapstructure:"port"`   // SMTP服务器端口
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type AccountConfig struct {
+	RequireEmail        bool          `mapstructure:"require_email"`         // 注册时是否必须填写邮箱
+	UnverifiedCanPost   bool          `mapstructure:"unverified_can_post"`   // 邮箱未验证的用户是否可以发帖
+	UnverifiedCanVote   bool          `mapstructure:"unverified_can_vote"`   // 邮箱未验证的用户是否可以投票
+	VerifyTokenDuration time.Duration `mapstructure:"verify_token_duration"` // 邮箱验证token的有效期
+}
+
 type RatelimitConfig struct {
 	FillInterval time.Duration `mapstructure:"fill_interval"`
 	Cap          int64         `mapstructure:"cap"`
@@ -72,6 +137,25 @@ func Init() (err error) {
 	// viper.SetConfigName("config") //指定配置文件名称
 	// viper.SetConfigType("yaml")    //指定文件类型
 	// viper.AddConfigPath("./conf/") //指定查找配置文件的路径
+	viper.SetDefault("refresh_token_grace", "30s")
+	viper.SetDefault("timeline.fan_out_threshold", 1000)
+	viper.SetDefault("timeline.max_len", 1000)
+	viper.SetDefault("password.min_length", 8)
+	viper.SetDefault("password.max_length", 72)
+	viper.SetDefault("password.require_lower", true)
+	viper.SetDefault("password.require_digit", true)
+	viper.SetDefault("password.reset_token_duration", "30m")
+	viper.SetDefault("mailer.driver", "log")
+	viper.SetDefault("mailer.from", "no-reply@lightning.local")
+	viper.SetDefault("mailer.port", 587)
+	viper.SetDefault("account.require_email", false)
+	viper.SetDefault("account.unverified_can_post", true)
+	viper.SetDefault("account.unverified_can_vote", true)
+	viper.SetDefault("account.verify_token_duration", "24h")
+	// 环境变量覆盖配置文件,如 LIGHTNING_JWT_SIGNING_KID 覆盖 jwt.signing_kid
+	viper.SetEnvPrefix("lightning")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 	err = viper.ReadInConfig() //读取配置文件信息
 	if err != nil {
 		// 读取配置文件失败